@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Agent is a coding agent option for the [c] pane.
+//
+// config.json may list agents as plain strings ("claude") or as objects;
+// a plain string is an agent whose binary is its name.
+type Agent struct {
+	Name        string            `json:"name"`
+	Binary      string            `json:"binary,omitempty"`
+	Args        []string          `json:"args,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	Dir         string            `json:"dir,omitempty"` // relative to the workspace directory unless absolute
+	VersionCmd  string            `json:"version_command,omitempty"`
+	InstallHint string            `json:"install_hint,omitempty"`
+}
+
+// UnmarshalJSON accepts either a bare agent name or a full agent object.
+func (a *Agent) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*a = Agent{Name: name}
+		return nil
+	}
+
+	type agentFields Agent
+	var fields agentFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*a = Agent(fields)
+	return nil
+}
+
+// MarshalJSON writes agents without extra settings back as plain strings so
+// existing config files keep their shape.
+func (a Agent) MarshalJSON() ([]byte, error) {
+	if a.Binary == "" && len(a.Args) == 0 && len(a.Env) == 0 && a.Dir == "" &&
+		a.VersionCmd == "" && a.InstallHint == "" {
+		return json.Marshal(a.Name)
+	}
+	type agentFields Agent
+	return json.Marshal(agentFields(a))
+}
+
+// isNone reports whether the agent pane should be left as an empty shell.
+func (a Agent) isNone() bool {
+	return a.Name == "" || a.Name == "none"
+}
+
+// binary returns the executable checked for and started in the agent pane.
+func (a Agent) binary() string {
+	if a.Binary != "" {
+		return a.Binary
+	}
+	return a.Name
+}
+
+// commandLine returns the exact shell command typed into the agent pane.
+func (a Agent) commandLine() string {
+	if a.isNone() {
+		return ""
+	}
+
+	var parts []string
+	keys := make([]string, 0, len(a.Env))
+	for k := range a.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, k+"="+shellQuote(a.Env[k]))
+	}
+
+	parts = append(parts, shellQuote(a.binary()))
+	for _, arg := range a.Args {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// workDir returns the directory the agent starts in for a workspace.
+func (a Agent) workDir(workspaceDir string) string {
+	if a.Dir == "" {
+		return workspaceDir
+	}
+	dir := expandHome(a.Dir)
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(workspaceDir, dir)
+}
+
+// agentNames lists agent names for flags, help text and lookups.
+func agentNames(agents []Agent) []string {
+	names := make([]string, len(agents))
+	for i, a := range agents {
+		names[i] = a.Name
+	}
+	return names
+}
+
+func findAgent(agents []Agent, name string) (Agent, bool) {
+	for _, a := range agents {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Agent{}, false
+}
+
+// agentVersionMsg delivers the first line of an agent's version command.
+type agentVersionMsg struct {
+	binary  string
+	version string
+}
+
+// probeAgentVersions starts the version command of each picked agent that
+// has not been asked yet. The preview only reads m.agentVersions, so
+// rendering never waits for a command.
+func (m *model) probeAgentVersions() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, agent := range m.selectedAgents() {
+		if agent.VersionCmd == "" {
+			continue
+		}
+		if _, asked := m.agentVersions[agent.binary()]; asked {
+			continue
+		}
+		m.agentVersions[agent.binary()] = ""
+		cmds = append(cmds, agentVersion(agent))
+	}
+	return tea.Batch(cmds...)
+}
+
+// agentVersion runs the agent's version command, giving up after two
+// seconds. Agents that are not installed report no version.
+func agentVersion(a Agent) tea.Cmd {
+	return func() tea.Msg {
+		msg := agentVersionMsg{binary: a.binary()}
+		if !commandExists(a.binary()) {
+			return msg
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if out, err := exec.CommandContext(ctx, "sh", "-c", a.VersionCmd).Output(); err == nil {
+			msg.version, _, _ = strings.Cut(strings.TrimSpace(string(out)), "\n")
+		}
+		return msg
+	}
+}
+
+// splitAgentNames splits an agent value such as "claude,codex", as used by
+// --agent, presets and project files.
+func splitAgentNames(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// selectedAgents returns the agents picked in the form, in list order.
+// Without a multi-select the highlighted agent is the selection.
+func (m model) selectedAgents() []Agent {
+	var agents []Agent
+	for i, a := range m.agentOptions {
+		picked := m.checkedAgents[a.Name]
+		if len(m.checkedAgents) == 0 {
+			picked = i == m.agentCursor
+		}
+		if picked && !a.isNone() {
+			agents = append(agents, a)
+		}
+	}
+	return agents
+}
+
+// agentValue is the form's agent selection as a comma separated list.
+func (m model) agentValue() string {
+	if len(m.checkedAgents) == 0 {
+		return m.agentOptions[m.agentCursor].Name
+	}
+	names := agentNames(m.selectedAgents())
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// toggleAgent adds the highlighted agent to the multi-select or removes
+// it. Removing the last one goes back to picking a single agent.
+func (m *model) toggleAgent() {
+	name := m.agentOptions[m.agentCursor].Name
+	if m.checkedAgents[name] {
+		delete(m.checkedAgents, name)
+		return
+	}
+	if m.checkedAgents == nil {
+		m.checkedAgents = map[string]bool{}
+	}
+	m.checkedAgents[name] = true
+}
+
+// selectAgents picks agents by name. One name selects it alone, several
+// turn on the multi-select.
+func (m *model) selectAgents(names []string) {
+	if len(names) == 0 {
+		return
+	}
+	m.checkedAgents = nil
+	if len(names) > 1 {
+		m.checkedAgents = map[string]bool{}
+		for _, name := range names {
+			m.checkedAgents[name] = true
+		}
+	}
+	for i, a := range m.agentOptions {
+		if a.Name == names[0] {
+			m.agentCursor = i
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAgentVersionsProbedInBackground(t *testing.T) {
+	cliEnv(t)
+	m := initialModel()
+	m.agentOptions = []Agent{{Name: "shell agent", Binary: "sh", VersionCmd: `printf 'v1.2\nbuild 7'`}}
+	m.agentCursor = 0
+	m.currentState = statePreview
+
+	// Rendering before the probe has finished shows no version
+	if strings.Contains(m.View(), "v1.2") {
+		t.Fatal("the preview ran the version command")
+	}
+
+	cmd := m.probeAgentVersions()
+	if cmd == nil {
+		t.Fatal("no version probe started")
+	}
+	if again := m.probeAgentVersions(); again != nil {
+		t.Error("a probe was started twice for the same agent")
+	}
+
+	next, _ := m.Update(cmd())
+	m = next.(model)
+	if got := m.agentVersions["sh"]; got != "v1.2" {
+		t.Errorf("version = %q, want the first line v1.2", got)
+	}
+	if !strings.Contains(m.View(), "v1.2") {
+		t.Errorf("the preview does not show the probed version:\n%s", m.View())
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Bookmark is a saved directory. An alias lets it be used as @alias in the
+// path input and on the command line.
+type Bookmark struct {
+	Alias string `json:"alias,omitempty"`
+	Path  string `json:"path"`
+}
+
+// BookmarksConfig stores bookmarks in bookmarks.json
+type BookmarksConfig struct {
+	Bookmarks []Bookmark `json:"bookmarks"`
+}
+
+func bookmarksFile() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "bookmarks.json"), nil
+}
+
+// loadBookmarks loads bookmarks from ~/.vinw-workspace/bookmarks.json
+func loadBookmarks() ([]Bookmark, error) {
+	file, err := bookmarksFile()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config BookmarksConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid bookmarks.json: %w", err)
+	}
+	return config.Bookmarks, nil
+}
+
+func saveBookmarks(bookmarks []Bookmark) error {
+	file, err := bookmarksFile()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(BookmarksConfig{Bookmarks: bookmarks}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, data, 0644)
+}
+
+// addBookmark bookmarks a directory. Bookmarking a directory again updates
+// its alias; an alias already used for another directory is an error.
+func addBookmark(bookmarks []Bookmark, bookmark Bookmark) ([]Bookmark, error) {
+	bookmark.Alias = strings.TrimPrefix(strings.TrimSpace(bookmark.Alias), "@")
+	if strings.ContainsAny(bookmark.Alias, " /") {
+		return bookmarks, fmt.Errorf("alias %q cannot contain spaces or slashes", bookmark.Alias)
+	}
+
+	var out []Bookmark
+	for _, b := range bookmarks {
+		if bookmark.Alias != "" && b.Alias == bookmark.Alias && b.Path != bookmark.Path {
+			return bookmarks, fmt.Errorf("@%s already points to %s", b.Alias, b.Path)
+		}
+		if b.Path != bookmark.Path {
+			out = append(out, b)
+		}
+	}
+	return append(out, bookmark), nil
+}
+
+func removeBookmark(bookmarks []Bookmark, path string) []Bookmark {
+	var out []Bookmark
+	for _, b := range bookmarks {
+		if b.Path != path {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// resolveBookmark turns "@alias" or "@alias/sub/dir" into a path under the
+// bookmarked directory. Other values are returned unchanged.
+func resolveBookmark(value string) (string, error) {
+	alias, ok := strings.CutPrefix(value, "@")
+	if !ok {
+		return value, nil
+	}
+	alias, rest, _ := strings.Cut(alias, "/")
+
+	bookmarks, err := loadBookmarks()
+	if err != nil {
+		return "", err
+	}
+	for _, b := range bookmarks {
+		if b.Alias == alias {
+			return filepath.Join(b.Path, rest), nil
+		}
+	}
+	return "", fmt.Errorf("no bookmark named @%s", alias)
+}
+
+// showBookmarks lists bookmarked directories in the directory chooser.
+func (m *model) showBookmarks() {
+	m.cancelDirectoryLoad()
+	bookmarks, err := loadBookmarks()
+	if err != nil {
+		m.err = err
+	}
+	m.bookmarks = bookmarks
+
+	homeDir, _ := os.UserHomeDir()
+	m.files = []fileEntry{}
+	for _, b := range bookmarks {
+		name := b.Path
+		if strings.HasPrefix(name, homeDir) {
+			name = "~" + strings.TrimPrefix(name, homeDir)
+		}
+		if b.Alias != "" {
+			name = "@" + b.Alias + "  " + name
+		}
+		m.files = append(m.files, fileEntry{
+			Name:  name,
+			Path:  b.Path,
+			IsDir: true,
+		})
+	}
+
+	m.browseMode = browseBookmarks
+	m.filteredFiles = m.files
+	m.cursor = 0
+	m.viewportStart = 0
+	m.searching = false
+	m.searchInput.Blur()
+	m.searchInput.SetValue("")
+	m.updateViewport()
+}
+
+// startBookmark asks for an alias for the highlighted directory, or the
+// current directory when a file or ".." is highlighted.
+func (m *model) startBookmark() tea.Cmd {
+	target := m.directory
+	if m.cursor < len(m.filteredFiles) {
+		entry := m.filteredFiles[m.cursor]
+		if entry.IsDir && entry.Name != ".." {
+			target = entry.Path
+		}
+	}
+
+	m.addingBookmark = true
+	m.bookmarkTarget = target
+	m.bookmarkAliasInput.SetValue("")
+	for _, b := range m.bookmarks {
+		if b.Path == target {
+			m.bookmarkAliasInput.SetValue(b.Alias)
+		}
+	}
+	m.bookmarkAliasInput.CursorEnd()
+	m.updateViewport() // Recalculate viewport with reduced space
+	return m.bookmarkAliasInput.Focus()
+}
+
+// updateBookmarkInput handles keys while naming a bookmark
+func (m model) updateBookmarkInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.addingBookmark = false
+		m.bookmarkAliasInput.Blur()
+		m.updateViewport() // Restore viewport to full size
+		return m, nil
+
+	case "enter":
+		bookmarks, err := addBookmark(m.bookmarks, Bookmark{
+			Alias: m.bookmarkAliasInput.Value(),
+			Path:  m.bookmarkTarget,
+		})
+		if err == nil {
+			err = saveBookmarks(bookmarks)
+		}
+		if err != nil {
+			m.statusMessage = statusMsg{text: err.Error(), isError: true}
+			return m, nil
+		}
+
+		m.bookmarks = bookmarks
+		m.addingBookmark = false
+		m.bookmarkAliasInput.Blur()
+		m.statusMessage = statusMsg{text: "✓ Bookmarked " + m.bookmarkTarget}
+		if m.browseMode == browseBookmarks {
+			m.showBookmarks()
+		}
+		m.updateViewport()
+		return m, nil
+
+	default:
+		var cmd tea.Cmd
+		m.bookmarkAliasInput, cmd = m.bookmarkAliasInput.Update(msg)
+		return m, cmd
+	}
+}
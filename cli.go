@@ -0,0 +1,511 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const cliUsage = `Usage:
+  vinw-workspace                 Start the interactive TUI
+  vinw-workspace launch [flags] [dir]
+                                 Launch a workspace without the TUI
+  vinw-workspace kill [flags] <session>
+                                 Kill a session, optionally removing its git worktree
+  vinw-workspace import [flags] <file>
+                                 Turn a tmuxinator or tmuxp file into a preset
+
+Run 'vinw-workspace <command> -h' for flags.
+`
+
+// usageError marks errors caused by bad arguments so they exit with status 2.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+func usageErrorf(format string, args ...any) error {
+	return usageError(fmt.Sprintf(format, args...))
+}
+
+// runCLI dispatches subcommands and returns the process exit code.
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	var err error
+	switch args[0] {
+	case "launch":
+		err = runLaunch(args[1:], stdout, stderr)
+	case "kill":
+		err = runKill(args[1:], stderr)
+	case "import":
+		err = runImport(args[1:], stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stderr, cliUsage)
+		return 0
+	default:
+		err = usageErrorf("unknown command %q", args[0])
+	}
+
+	if err == nil {
+		return 0
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	fmt.Fprintf(stderr, "Error: %v\n", err)
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprint(stderr, "\n"+cliUsage)
+		return 2
+	}
+	return 1
+}
+
+// runLaunch starts a workspace from flags, reusing the same dependency and
+// session checks as the preview screen. With --export it prints the
+// workspace instead.
+func runLaunch(args []string, stdout, stderr io.Writer) error {
+	config, _ := loadConfig()
+
+	fs := flag.NewFlagSet("launch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dir := fs.String("dir", ".", "project directory or @bookmark (a "+projectFileName+" there supplies defaults)")
+	session := fs.String("session", "dev", "tmux session name")
+	terminal := fs.String("terminal", firstOr(config.TerminalOptions, "shell"), "terminal option: "+strings.Join(config.TerminalOptions, ", "))
+	agent := fs.String("agent", firstOr(agentNames(config.AgentOptions), "none"), "coding agents, comma separated: "+strings.Join(agentNames(config.AgentOptions), ", "))
+	command := fs.String("command", "", "custom command name or shell command for the terminal pane")
+	layoutName := fs.String("layout", "", "layout name (default: first configured layout)")
+	onConflict := fs.String("on-conflict", conflictFail, "when the session exists: "+strings.Join(conflictPolicies, ", "))
+	presetName := fs.String("preset", "", "saved preset to launch; other flags override its values")
+	worktreeBranch := fs.String("worktree", "", "branch to check out in a git worktree and launch in; names the session unless --session is set")
+	export := fs.String("export", "", "print the workspace instead of launching it: "+strings.Join(exportFormats, ", "))
+	dryRun := fs.Bool("dry-run", false, "print the tmux commands the launch would run without running them")
+	var windows windowFlag
+	fs.Var(&windows, "window", "extra window as name:command, repeatable (e.g. --window 'server:npm run dev')")
+
+	// The directory may also be given as a positional argument, before or
+	// after the flags
+	var positional []string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		positional, args = args[:1], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageErrorf("%v", err)
+	}
+	positional = append(positional, fs.Args()...)
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	switch {
+	case len(positional) == 1 && !set["dir"]:
+		*dir = positional[0]
+		set["dir"] = true
+	case len(positional) > 0:
+		return usageErrorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+
+	var preset Preset
+	if *presetName != "" {
+		presets, err := loadPresets()
+		if err != nil {
+			return err
+		}
+		var ok bool
+		if preset, ok = findPreset(presets.Presets, *presetName); !ok {
+			names := make([]string, len(presets.Presets))
+			for i, p := range presets.Presets {
+				names[i] = p.Name
+			}
+			return usageErrorf("unknown preset %q (presets: %s)", *presetName, strings.Join(names, ", "))
+		}
+		if !set["dir"] {
+			*dir = preset.Dir
+		}
+	}
+
+	bookmarkDir, err := resolveBookmark(*dir)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	absDir, err := filepath.Abs(expandPath(bookmarkDir))
+	if err != nil {
+		return fmt.Errorf("invalid directory %q: %w", *dir, err)
+	}
+	if info, err := os.Stat(absDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("directory %q does not exist", absDir)
+	}
+
+	// Flags win over the preset, then the project file, then the global
+	// config
+	project, _, err := loadProjectConfig(absDir)
+	if err != nil {
+		return err
+	}
+	config = mergeProjectConfig(config, project)
+	if !set["session"] {
+		overrideWith(session, project.Session, preset.Session)
+	}
+	if !set["terminal"] {
+		overrideWith(terminal, project.Terminal, preset.Terminal)
+	}
+	if !set["agent"] {
+		overrideWith(agent, project.Agent, preset.Agent)
+	}
+	if !set["layout"] {
+		overrideWith(layoutName, project.Layout, preset.Layout)
+	}
+	if !set["worktree"] {
+		*worktreeBranch = preset.Worktree
+	}
+	if *worktreeBranch != "" && !set["session"] {
+		*session = branchSessionName(*worktreeBranch)
+	}
+	if !set["command"] {
+		overrideWith(command, project.Command, preset.Command)
+	}
+	commandValue := *command
+	extraWindows := mergeWindows(project.Windows, preset.Windows, windows)
+	globalCommands, _ := loadWorkspaceCommands()
+	*command = resolveCommand(mergeProjectCommands(globalCommands, project), *command)
+
+	if !contains(config.TerminalOptions, *terminal) {
+		return usageErrorf("unknown terminal %q (options: %s)", *terminal, strings.Join(config.TerminalOptions, ", "))
+	}
+	var selectedAgents []Agent
+	for _, name := range splitAgentNames(*agent) {
+		selected, ok := findAgent(config.AgentOptions, name)
+		if !ok {
+			return usageErrorf("unknown agent %q (options: %s)", name, strings.Join(agentNames(config.AgentOptions), ", "))
+		}
+		if !selected.isNone() {
+			selectedAgents = append(selectedAgents, selected)
+		}
+	}
+
+	layout, ok := findLayout(config.Layouts, *layoutName)
+	if !ok {
+		names := make([]string, len(config.Layouts))
+		for i, l := range config.Layouts {
+			names[i] = l.Name
+		}
+		return usageErrorf("unknown layout %q (options: %s)", *layoutName, strings.Join(names, ", "))
+	}
+
+	if !contains(conflictPolicies, *onConflict) {
+		return usageErrorf("unknown --on-conflict %q (options: %s)", *onConflict, strings.Join(conflictPolicies, ", "))
+	}
+	if *export != "" && !contains(exportFormats, *export) {
+		return usageErrorf("unknown --export %q (options: %s)", *export, strings.Join(exportFormats, ", "))
+	}
+	if *export != "" && *dryRun {
+		return usageErrorf("--export and --dry-run cannot be combined")
+	}
+
+	spec := workspaceSpec{
+		Dir:       absDir,
+		Session:   *session,
+		Terminal:  *terminal,
+		Agents:    selectedAgents,
+		CustomCmd: *command,
+		Layout:    layout,
+		PaneStart: config.PaneStart,
+		Windows:   extraWindows,
+	}
+
+	// Exports and dry runs only describe the launch, so the worktree is
+	// planned but not created
+	if *export != "" || *dryRun {
+		var worktree *worktreePlan
+		if *worktreeBranch != "" {
+			plan, err := planWorktree(absDir, *worktreeBranch, config.WorktreeDir)
+			if err != nil {
+				return err
+			}
+			worktree = &plan
+			spec.Dir, spec.Worktree = plan.LaunchDir, plan.Path
+		}
+		spec.SessionID = generateSessionID(spec.Dir)
+		if *dryRun {
+			return printDryRun(stdout, spec, worktree, *onConflict)
+		}
+		out, err := exportWorkspace(*export, spec, worktree)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(stdout, out)
+		return nil
+	}
+
+	// Reusing an existing session needs none of the launch prerequisites
+	if *onConflict == conflictAttach && sessionExists(*session) {
+		return attachSession(*session)
+	}
+
+	var missing []string
+	for _, dep := range checkDependencies(*terminal, selectedAgents) {
+		if dep.Required && !dep.Available {
+			if dep.InstallHint != "" {
+				missing = append(missing, fmt.Sprintf("%s (%s)", dep.Name, dep.InstallHint))
+			} else {
+				missing = append(missing, dep.Name)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing dependencies: %s", strings.Join(missing, ", "))
+	}
+
+	if *onConflict == conflictFail && sessionExists(*session) {
+		msg := fmt.Sprintf("session '%s' already exists", *session)
+		if info, err := describeSession(*session); err == nil {
+			msg += fmt.Sprintf(" (directory: %s, panes: %s)", info.Path, strings.Join(info.PaneCommands, ", "))
+		}
+		return fmt.Errorf("%s - use --on-conflict attach, suffix or recreate", msg)
+	}
+
+	launchDir, worktree := absDir, ""
+	if *worktreeBranch != "" {
+		worktree, launchDir, err = ensureWorktree(absDir, *worktreeBranch, config.WorktreeDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	name, _, err := resolveSessionConflict(*session, *onConflict)
+	if err != nil {
+		return err
+	}
+
+	spec.Dir, spec.Session, spec.Worktree = launchDir, name, worktree
+	spec.SessionID = generateSessionID(launchDir)
+	plan, err := buildLaunchPlan(spec)
+	if err != nil {
+		return err
+	}
+	if err := executeLaunchPlan(tmuxServer, plan); err != nil {
+		return err
+	}
+
+	// Only launches that came up are remembered and ranked by frecency
+	recordRecent(Preset{
+		Dir:      absDir,
+		Session:  name,
+		Terminal: *terminal,
+		Agent:    *agent,
+		Command:  commandValue,
+		Layout:   layout.Name,
+		Worktree: *worktreeBranch,
+		Windows:  mergeWindows(preset.Windows, windows),
+	})
+	recordDirectory(absDir)
+
+	return attachSession(name)
+}
+
+// printDryRun prints the launch plan, noting what the conflict policy would
+// do with a session that already exists.
+func printDryRun(stdout io.Writer, spec workspaceSpec, worktree *worktreePlan, onConflict string) error {
+	if sessionExists(spec.Session) {
+		switch onConflict {
+		case conflictAttach:
+			fmt.Fprintf(stdout, "Session '%s' exists - the launch would attach to it:\n    %s\n", spec.Session, scriptCommand([]string{"attach-session", "-t", spec.Session}))
+			return nil
+		case conflictSuffix:
+			name := nextSessionName(spec.Session)
+			fmt.Fprintf(stdout, "Session '%s' exists - launching as '%s'\n", spec.Session, name)
+			spec.Session = name
+		case conflictRecreate:
+			fmt.Fprintf(stdout, "Session '%s' exists - it would be killed first:\n    %s\n", spec.Session, scriptCommand([]string{"kill-session", "-t", spec.Session + ":"}))
+		default:
+			return fmt.Errorf("session '%s' already exists - use --on-conflict attach, suffix or recreate", spec.Session)
+		}
+	}
+
+	plan, err := buildLaunchPlan(spec)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(stdout, plan.dryRun(worktree))
+	return nil
+}
+
+// runKill kills a session. With --remove-worktree the git worktree the
+// session was launched in is removed too, refusing to throw away
+// uncommitted changes unless --force is given.
+func runKill(args []string, stderr io.Writer) error {
+	fs := flag.NewFlagSet("kill", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	removeTree := fs.Bool("remove-worktree", false, "also remove the git worktree the session was launched in")
+	force := fs.Bool("force", false, "remove the worktree even if it has uncommitted changes")
+
+	// The session may come before or after the flags
+	var positional []string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		positional, args = args[:1], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageErrorf("%v", err)
+	}
+	positional = append(positional, fs.Args()...)
+	if len(positional) != 1 {
+		return usageErrorf("kill needs exactly one session name")
+	}
+	session := positional[0]
+
+	if !sessionExists(session) {
+		return fmt.Errorf("session '%s' does not exist", session)
+	}
+	worktree, err := sessionWorktree(session)
+	if err != nil {
+		return err
+	}
+
+	// Check before killing so a refusal leaves everything as it was
+	if *removeTree {
+		if worktree == "" {
+			return fmt.Errorf("session '%s' was not launched in a worktree", session)
+		}
+		changes, err := worktreeChanges(worktree)
+		if err != nil {
+			return err
+		}
+		if changes > 0 && !*force {
+			return fmt.Errorf("worktree %s has %d uncommitted changes - commit them or use --force", worktree, changes)
+		}
+	}
+
+	if err := killSession(session); err != nil {
+		return err
+	}
+	if !*removeTree {
+		if worktree != "" {
+			fmt.Fprintf(stderr, "Kept worktree %s (use --remove-worktree to delete it)\n", worktree)
+		}
+		return nil
+	}
+	return removeWorktree(worktree, *force)
+}
+
+// runImport converts a tmuxinator or tmuxp file into a preset, or a
+// project file with --project, and lists what could not be converted.
+func runImport(args []string, stderr io.Writer) error {
+	config, _ := loadConfig()
+
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	name := fs.String("name", "", "preset name (default: the session name in the file)")
+	project := fs.Bool("project", false, "write a "+projectFileName+" in the session's root instead of a preset")
+	force := fs.Bool("force", false, "overwrite an existing preset or project file")
+
+	// The file may come before or after the flags
+	var positional []string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		positional, args = args[:1], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageErrorf("%v", err)
+	}
+	positional = append(positional, fs.Args()...)
+	if len(positional) != 1 {
+		return usageErrorf("import needs exactly one tmuxinator or tmuxp file")
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	result, err := importSessionFile(positional[0], cwd, config.AgentOptions)
+	if err != nil {
+		return err
+	}
+	preset := result.Preset
+	if *name != "" {
+		preset.Name = *name
+	}
+	if preset.Name == "" {
+		return usageErrorf("the file has no session name - use --name")
+	}
+
+	if *project {
+		if info, err := os.Stat(preset.Dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("directory %q does not exist", preset.Dir)
+		}
+		file := filepath.Join(preset.Dir, projectFileName)
+		if _, err := os.Stat(file); err == nil && !*force {
+			return fmt.Errorf("%s already exists - use --force to overwrite it", file)
+		}
+		if err := saveProjectConfig(preset.Dir, importProject(preset)); err != nil {
+			return err
+		}
+		fmt.Fprintf(stderr, "Wrote %s from %s\n", file, result.Format)
+	} else {
+		presets, err := loadPresets()
+		if err != nil {
+			return err
+		}
+		if _, exists := findPreset(presets.Presets, preset.Name); exists && !*force {
+			return fmt.Errorf("preset '%s' already exists - use --name or --force", preset.Name)
+		}
+		if err := savePreset(preset); err != nil {
+			return err
+		}
+		fmt.Fprintf(stderr, "Imported preset '%s' from %s - launch it with: vinw-workspace launch --preset %s\n", preset.Name, result.Format, shellQuote(preset.Name))
+	}
+
+	if len(result.Unmapped) > 0 {
+		fmt.Fprintln(stderr, "Not imported:")
+		for _, item := range result.Unmapped {
+			fmt.Fprintf(stderr, "  - %s\n", item)
+		}
+	}
+	return nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+}
+
+// overrideWith sets target to the last non-empty value, so later values
+// take precedence.
+func overrideWith(target *string, values ...string) {
+	for _, value := range values {
+		if value != "" {
+			*target = value
+		}
+	}
+}
+
+func firstOr(options []string, fallback string) string {
+	if len(options) > 0 {
+		return options[0]
+	}
+	return fallback
+}
+
+func contains(options []string, value string) bool {
+	for _, opt := range options {
+		if opt == value {
+			return true
+		}
+	}
+	return false
+}
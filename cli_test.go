@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// cliEnv gives a CLI test an empty home directory and a fake tmux server.
+func cliEnv(t *testing.T) *fakeTmux {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TMUX", "")
+	fake := newFakeTmux()
+	saved := tmuxServer
+	tmuxServer = fake
+	t.Cleanup(func() { tmuxServer = saved })
+	return fake
+}
+
+func TestLaunchCommandPrecedence(t *testing.T) {
+	tests := []struct {
+		name          string
+		presetCommand string
+		flags         []string
+		want          string
+	}{
+		{"project file", "", nil, "npm run dev"},
+		{"preset", "make watch", nil, "make watch"},
+		{"flag", "make watch", []string{"--command", "go test ./..."}, "go test ./..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cliEnv(t)
+			dir := t.TempDir()
+			if err := saveProjectConfig(dir, ProjectConfig{Command: "npm run dev"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := savePreset(Preset{Name: "api", Dir: dir, Session: "api", Terminal: "shell", Agent: "none", Command: tt.presetCommand}); err != nil {
+				t.Fatal(err)
+			}
+
+			var stdout, stderr bytes.Buffer
+			args := append([]string{"--preset", "api", "--dry-run"}, tt.flags...)
+			if err := runLaunch(args, &stdout, &stderr); err != nil {
+				t.Fatalf("runLaunch: %v\n%s", err, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), shellQuote(tt.want)) {
+				t.Errorf("dry run does not start %q:\n%s", tt.want, stdout.String())
+			}
+		})
+	}
+}
+
+// installFakes puts do-nothing executables with the given names first on
+// PATH so dependency checks pass.
+func installFakes(t *testing.T, names ...string) {
+	t.Helper()
+	bin := t.TempDir()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestLaunchRecordsOnlySuccessfulLaunches(t *testing.T) {
+	fake := cliEnv(t)
+	installFakes(t, "vinw", "tmux")
+	dir := t.TempDir()
+	launch := func() error {
+		var stdout, stderr bytes.Buffer
+		return runLaunch([]string{"--dir", dir, "--session", "api", "--agent", "none"}, &stdout, &stderr)
+	}
+	recorded := func() (int, int) {
+		presets, err := loadPresets()
+		if err != nil {
+			t.Fatal(err)
+		}
+		history, err := loadHistory()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(presets.Recent), len(history.Directories)
+	}
+
+	fake.Fail = map[string]string{"split-window": "no space for new pane"}
+	if err := launch(); err == nil {
+		t.Fatal("launch succeeded")
+	}
+	if recent, dirs := recorded(); recent != 0 || dirs != 0 {
+		t.Errorf("failed launch recorded: %d recent launches, %d directories", recent, dirs)
+	}
+
+	fake.Fail = nil
+	if err := launch(); err != nil {
+		t.Fatal(err)
+	}
+	if recent, dirs := recorded(); recent != 1 || dirs != 1 {
+		t.Errorf("launch recorded as %d recent launches, %d directories; want 1 and 1", recent, dirs)
+	}
+	if fake.Attached != "api" {
+		t.Errorf("attached to %q, want api", fake.Attached)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// How pane commands are started
+const (
+	paneStartKeys    = "keys"    // type the command into the pane's shell
+	paneStartRespawn = "respawn" // replace the pane's shell with the command
+)
+
+// paneStart is how one layout pane is started: the directory tmux opens it
+// in and the shell command to run there ("" leaves a plain shell).
+type paneStart struct {
+	Dir     string
+	Command string
+}
+
+// agentPanes are the layout panes that hold the selected agents, in order.
+// Agents that do not fit in a layout get a window of their own.
+var agentPanes = []rune{paneAgent, 'd', 'e', 'f', 'g', 'h'}
+
+// placeAgents assigns agents to the agent panes of a layout and returns
+// the agents left over.
+func placeAgents(root *layoutNode, agents []Agent) (map[rune]Agent, []Agent) {
+	inLayout := map[rune]bool{}
+	for _, pane := range root.panes() {
+		inLayout[pane] = true
+	}
+
+	placed := map[rune]Agent{}
+	for _, pane := range agentPanes {
+		if len(agents) == 0 {
+			break
+		}
+		if inLayout[pane] {
+			placed[pane] = agents[0]
+			agents = agents[1:]
+		}
+	}
+	return placed, agents
+}
+
+// paneCommand returns the command run in a layout pane without an agent,
+// or "" to leave the pane as a plain shell.
+func paneCommand(pane rune, terminal, sessionID, customCmd string) string {
+	switch pane {
+	case paneVinw:
+		return "vinw"
+	case paneViewer:
+		return "vinw-viewer " + shellQuote(sessionID)
+	case paneTerminal:
+		if customCmd != "" {
+			return customCmd
+		}
+		if terminal == "nextui" {
+			return "nextui"
+		}
+	}
+	return ""
+}
+
+// agentStart is how an agent's pane is started.
+func agentStart(agent Agent, workspaceDir string) paneStart {
+	return paneStart{
+		Dir:     agent.workDir(workspaceDir),
+		Command: agent.commandLine(),
+	}
+}
+
+// paneStarts resolves the directory and command of every pane in a layout.
+func paneStarts(root *layoutNode, spec workspaceSpec) map[rune]paneStart {
+	placed, _ := placeAgents(root, spec.Agents)
+	starts := map[rune]paneStart{}
+	for _, pane := range root.panes() {
+		if agent, ok := placed[pane]; ok {
+			starts[pane] = agentStart(agent, spec.Dir)
+			continue
+		}
+		starts[pane] = paneStart{
+			Dir:     spec.Dir,
+			Command: paneCommand(pane, spec.Terminal, spec.SessionID, spec.CustomCmd),
+		}
+	}
+	return starts
+}
+
+// startPaneCommands returns the tmux commands that start a pane's command.
+//
+// In keys mode the command is sent literally so tmux never reads words in
+// it as key names. In respawn mode the pane's shell is replaced by one
+// that runs the command and then stays open as an interactive shell.
+func startPaneCommands(mode, paneID string, start paneStart) []tmuxCommand {
+	if start.Command == "" {
+		return nil
+	}
+
+	if mode == paneStartRespawn {
+		shell := userShell()
+		script := start.Command + "; exec " + shellQuote(shell)
+		return []tmuxCommand{
+			respawnPane{Target: paneID, Dir: start.Dir, Command: []string{shell, "-c", script}},
+		}
+	}
+
+	return []tmuxCommand{
+		sendKeys{Target: paneID, Keys: start.Command, Literal: true},
+		sendKeys{Target: paneID, Keys: "Enter"},
+	}
+}
+
+func userShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
+
+// shellQuote quotes a word for POSIX shells, leaving plain words untouched.
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:,@%+", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+var hostileDirs = []struct {
+	name string
+	dir  string
+}{
+	{"plain", "/home/me/project"},
+	{"spaces", "/home/me/my project"},
+	{"single quote", "/home/me/it's here"},
+	{"double quote", `/home/me/say "hi"`},
+	{"dollar", "/home/me/$HOME"},
+	{"semicolon", "/home/me/a;rm -rf /"},
+	{"backticks", "/home/me/`whoami`"},
+	{"backslash", `/home/me/back\slash`},
+	{"newline", "/home/me/two\nlines"},
+	{"glob", "/home/me/*"},
+	{"empty", ""},
+}
+
+func TestShellQuoteRoundTrip(t *testing.T) {
+	for _, tt := range hostileDirs {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := exec.Command("sh", "-c", "printf %s "+shellQuote(tt.dir)).Output()
+			if err != nil {
+				t.Fatalf("sh failed: %v", err)
+			}
+			if string(out) != tt.dir {
+				t.Errorf("shellQuote(%q) came back as %q", tt.dir, out)
+			}
+		})
+	}
+}
+
+func TestSplitWindowKeepsDir(t *testing.T) {
+	for _, tt := range hostileDirs {
+		t.Run(tt.name, func(t *testing.T) {
+			args := splitWindow{Target: "%1", Dir: tt.dir, Horizontal: true, Percent: 80}.args()
+			if got := argAfter(args, "-c"); got != tt.dir {
+				t.Errorf("start directory = %q, want %q", got, tt.dir)
+			}
+			if got := argAfter(args, "-t"); got != "%1" {
+				t.Errorf("target = %q, want %%1", got)
+			}
+		})
+	}
+}
+
+func TestStartPaneCommands(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+
+	for _, tt := range hostileDirs {
+		t.Run(tt.name, func(t *testing.T) {
+			start := paneStart{Dir: tt.dir, Command: "echo " + shellQuote(tt.dir)}
+
+			keys := startPaneCommands(paneStartKeys, "%2", start)
+			wantKeys := []tmuxCommand{
+				sendKeys{Target: "%2", Keys: start.Command, Literal: true},
+				sendKeys{Target: "%2", Keys: "Enter"},
+			}
+			if !reflect.DeepEqual(keys, wantKeys) {
+				t.Fatalf("keys mode: got %#v, want %#v", keys, wantKeys)
+			}
+			if args := keys[0].args(); argAfter(args, "-R") != "-t" || argAfter(args, "-t") != "%2" || argAfter(args, "%2") != "-l" {
+				t.Errorf("keys mode must send the command literally: %q", args)
+			}
+
+			respawn := startPaneCommands(paneStartRespawn, "%2", start)
+			if len(respawn) != 1 {
+				t.Fatalf("respawn mode: got %d commands, want 1", len(respawn))
+			}
+			args := respawn[0].args()
+			if got := argAfter(args, "-c"); got != tt.dir {
+				t.Errorf("respawn directory = %q, want %q", got, tt.dir)
+			}
+			want := []string{"/bin/sh", "-c", start.Command + "; exec /bin/sh"}
+			got := args[len(args)-3:]
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("respawn command = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStartPaneCommandsEmptyCommand(t *testing.T) {
+	for _, mode := range []string{paneStartKeys, paneStartRespawn, ""} {
+		if args := startPaneCommands(mode, "%3", paneStart{Dir: "/tmp"}); args != nil {
+			t.Errorf("mode %q: got %q for an empty command, want nothing", mode, args)
+		}
+	}
+}
+
+func TestAgentCommandLineQuoting(t *testing.T) {
+	agent := Agent{
+		Name:   "claude",
+		Binary: "/opt/my tools/claude",
+		Args:   []string{"--model", "it's fine", "$HOME"},
+		Env:    map[string]string{"B": "two words", "A": "1"},
+	}
+
+	got := agent.commandLine()
+	want := `A=1 B='two words' '/opt/my tools/claude' --model 'it'\''s fine' '$HOME'`
+	if got != want {
+		t.Errorf("commandLine() = %s, want %s", got, want)
+	}
+}
+
+// argAfter returns the argument that follows flag, or "" if there is none.
+func argAfter(args []string, flag string) string {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+	return ""
+}
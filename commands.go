@@ -53,6 +53,27 @@ func (d commandDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 	fmt.Fprintf(w, "%s\n%s", title, desc)
 }
 
+// setWorkspaceCommands replaces the commands and refreshes the list items
+func (m *model) setWorkspaceCommands(commands []WorkspaceCommand) {
+	m.workspaceCommands = commands
+	items := make([]list.Item, len(commands))
+	for i, c := range commands {
+		desc := c.Description
+		if c.Project {
+			desc = "[project] " + desc
+		}
+		if m.hasWindow(c.Name) {
+			desc = "[window] " + desc
+		}
+		items[i] = commandItem{
+			name:        c.Name,
+			command:     c.Command,
+			description: desc,
+		}
+	}
+	m.commandsList.SetItems(items)
+}
+
 // updateCommands handles the custom commands list view
 func updateCommands(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -86,15 +107,7 @@ func updateCommands(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
 					saveWorkspaceCommands(m.workspaceCommands)
 
 					// Update list items
-					items := make([]list.Item, len(m.workspaceCommands))
-					for i, c := range m.workspaceCommands {
-						items[i] = commandItem{
-							name:        c.Name,
-							command:     c.Command,
-							description: c.Description,
-						}
-					}
-					m.commandsList.SetItems(items)
+					m.setWorkspaceCommands(m.workspaceCommands)
 
 					// Reset inputs
 					m.commandNameInput.SetValue("")
@@ -140,7 +153,7 @@ func updateCommands(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
 		case "esc":
 			// Go back to form
 			m.currentState = stateForm
-			m.focusIndex = 1 // Back to session name
+			m.focusIndex = focusSession // Back to session name
 			return m, nil
 		case "a":
 			// Start adding a command
@@ -159,15 +172,17 @@ func updateCommands(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
 					saveWorkspaceCommands(m.workspaceCommands)
 
 					// Update list items
-					items := make([]list.Item, len(m.workspaceCommands))
-					for i, c := range m.workspaceCommands {
-						items[i] = commandItem{
-							name:        c.Name,
-							command:     c.Command,
-							description: c.Description,
-						}
-					}
-					m.commandsList.SetItems(items)
+					m.setWorkspaceCommands(m.workspaceCommands)
+				}
+			}
+			return m, nil
+		case "w":
+			// Run the command in a window of its own next to the layout
+			if len(m.workspaceCommands) > 0 {
+				idx := m.commandsList.Index()
+				if idx >= 0 && idx < len(m.workspaceCommands) {
+					m.toggleCommandWindow(m.workspaceCommands[idx])
+					m.setWorkspaceCommands(m.workspaceCommands)
 				}
 			}
 			return m, nil
@@ -181,7 +196,7 @@ func updateCommands(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
 			}
 			// Go back to form
 			m.currentState = stateForm
-			m.focusIndex = 1
+			m.focusIndex = focusSession
 			return m, nil
 		}
 	}
@@ -258,7 +273,7 @@ func viewCommands(m model) string {
 	}
 
 	// Help text
-	helpText := "a: add • d: delete • enter: select • esc: back"
+	helpText := "a: add • d: delete • enter: select • w: own window • esc: back"
 	if len(m.workspaceCommands) == 0 {
 		helpText = "a: add command • esc: back"
 	}
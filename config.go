@@ -8,7 +8,13 @@ import (
 
 type Config struct {
 	TerminalOptions []string `json:"terminal_options"`
-	AgentOptions    []string `json:"agent_options"`
+	AgentOptions    []Agent  `json:"agent_options"`
+	Layouts         []Layout `json:"layouts,omitempty"`
+	PaneStart       string   `json:"pane_start,omitempty"` // "keys" (default) or "respawn"
+	ProjectRoots    []string `json:"project_roots,omitempty"`
+	ScanDepth       int      `json:"scan_depth,omitempty"`
+	ScanSkip        []string `json:"scan_skip,omitempty"`
+	WorktreeDir     string   `json:"worktree_dir,omitempty"` // default: <repo>-worktrees next to the repo
 }
 
 // WorkspaceCommand represents a custom command to run in terminal pane
@@ -16,6 +22,7 @@ type WorkspaceCommand struct {
 	Name        string `json:"name"`
 	Command     string `json:"command"`
 	Description string `json:"description"`
+	Project     bool   `json:"-"` // from a project file, never saved globally
 }
 
 // WorkspaceConfig stores custom commands for workspaces
@@ -25,7 +32,14 @@ type WorkspaceConfig struct {
 
 var defaultConfig = Config{
 	TerminalOptions: []string{"shell", "nextui"},
-	AgentOptions:    []string{"claude", "opencode", "crush", "codex", "none"},
+	AgentOptions: []Agent{
+		{Name: "claude"},
+		{Name: "opencode"},
+		{Name: "crush"},
+		{Name: "codex"},
+		{Name: "none"},
+	},
+	Layouts: defaultLayouts,
 }
 
 func getConfigDir() (string, error) {
@@ -65,6 +79,11 @@ func loadConfig() (Config, error) {
 		return defaultConfig, nil
 	}
 
+	// Configs written before layouts existed get the built-in ones
+	if len(config.Layouts) == 0 {
+		config.Layouts = defaultLayouts
+	}
+
 	return config, nil
 }
 
@@ -133,7 +152,13 @@ func saveWorkspaceCommands(commands []WorkspaceCommand) error {
 
 	confFile := filepath.Join(vinwDir, "workspace.conf")
 
-	config := WorkspaceConfig{Commands: commands}
+	// Project commands belong to their project file
+	config := WorkspaceConfig{Commands: []WorkspaceCommand{}}
+	for _, cmd := range commands {
+		if !cmd.Project {
+			config.Commands = append(config.Commands, cmd)
+		}
+	}
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return err
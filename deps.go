@@ -5,12 +5,14 @@ import (
 )
 
 type DependencyStatus struct {
-	Name      string
-	Available bool
-	Required  bool
+	Name        string
+	Available   bool
+	Required    bool
+	Version     string // from the agent's version command, once it has run
+	InstallHint string // shown when the dependency is missing
 }
 
-func checkDependencies(terminal, agent string) []DependencyStatus {
+func checkDependencies(terminal string, agents []Agent) []DependencyStatus {
 	deps := []DependencyStatus{
 		{Name: "vinw", Available: commandExists("vinw"), Required: true},
 		{Name: "tmux", Available: commandExists("tmux"), Required: true},
@@ -24,12 +26,19 @@ func checkDependencies(terminal, agent string) []DependencyStatus {
 		})
 	}
 
-	if agent != "none" && agent != "" {
-		deps = append(deps, DependencyStatus{
-			Name:      agent,
-			Available: commandExists(agent),
-			Required:  true,
-		})
+	checked := map[string]bool{}
+	for _, agent := range agents {
+		if agent.isNone() || checked[agent.binary()] {
+			continue
+		}
+		checked[agent.binary()] = true
+		dep := DependencyStatus{
+			Name:        agent.binary(),
+			Available:   commandExists(agent.binary()),
+			Required:    true,
+			InstallHint: agent.InstallHint,
+		}
+		deps = append(deps, dep)
 	}
 
 	return deps
@@ -1,22 +1,39 @@
 package main
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func (m *model) loadDirectory(path string) {
-	entries, err := os.ReadDir(path)
-	if err != nil {
-		return
-	}
+// dirPageSize is how many entries are read from a directory at a time.
+// Larger directories show the first page and load the rest on request.
+const dirPageSize = 1000
+
+// dirLoadedMsg delivers a page of directory entries. load identifies the
+// load that produced it; dir is the open directory when more entries remain.
+type dirLoadedMsg struct {
+	load    int
+	path    string
+	entries []fileEntry
+	dir     *os.File
+	err     error
+}
 
+// loadDirectory switches the chooser to path and starts reading it in the
+// background. Any load still running for another directory is cancelled.
+func (m *model) loadDirectory(path string) tea.Cmd {
+	m.cancelDirectoryLoad()
 	m.files = []fileEntry{}
 	m.directory = path
+	m.browseMode = browseFiles
+	m.dirErr = nil
+	m.dirLoading = true
 
 	// Add parent directory option if not root
 	if path != "/" && path != filepath.Dir(path) {
@@ -28,63 +45,210 @@ func (m *model) loadDirectory(path string) {
 		})
 	}
 
-	// Sort directories first, then files
-	sort.Slice(entries, func(i, j int) bool {
-		if entries[i].IsDir() != entries[j].IsDir() {
-			return entries[i].IsDir()
-		}
-		return entries[i].Name() < entries[j].Name()
-	})
-
-	for _, entry := range entries {
-		// Skip hidden files
-		if strings.HasPrefix(entry.Name(), ".") {
-			continue
-		}
-
-		m.files = append(m.files, fileEntry{
-			Name:  entry.Name(),
-			Path:  filepath.Join(path, entry.Name()),
-			IsDir: entry.IsDir(),
-		})
+	// Prefill the form from a project file when the directory changes;
+	// reloading the same directory keeps what was picked on the form since
+	if path != m.projectDir {
+		m.applyProject(path)
 	}
 
 	m.filteredFiles = m.files
 	m.cursor = 0
+	m.viewportStart = 0
 	m.searching = false
 	m.searchInput.Blur()
 	m.searchInput.SetValue("")
 	m.updateViewport()
+
+	return tea.Batch(openDirectory(m.dirLoadID, path, m.showHidden), m.spinner.Tick)
 }
 
-func fuzzyMatch(pattern, text string) bool {
-	pattern = strings.ToLower(pattern)
-	text = strings.ToLower(text)
+// dirErrorText explains why a directory could not be listed.
+func dirErrorText(err error) string {
+	if os.IsPermission(err) {
+		return "✗ Permission denied - this directory cannot be listed"
+	}
+	return "✗ " + err.Error()
+}
 
-	if pattern == "" {
-		return true
+// cancelDirectoryLoad stops listening for the current directory load and
+// closes the directory if it was left open for more pages.
+func (m *model) cancelDirectoryLoad() {
+	m.dirLoadID++
+	m.dirLoading = false
+	if m.dirMore != nil {
+		m.dirMore.Close()
+		m.dirMore = nil
 	}
+}
 
-	patternIdx := 0
-	for _, char := range text {
-		if patternIdx < len(pattern) && rune(pattern[patternIdx]) == char {
-			patternIdx++
+// loadMoreEntries reads the next page of a directory that had more entries
+// than dirPageSize.
+func (m *model) loadMoreEntries() tea.Cmd {
+	if m.dirMore == nil || m.dirLoading {
+		return nil
+	}
+	dir := m.dirMore
+	m.dirMore = nil
+	m.dirLoading = true
+	return tea.Batch(readDirectoryPage(m.dirLoadID, m.directory, dir, m.showHidden), m.spinner.Tick)
+}
+
+func openDirectory(load int, path string, showHidden bool) tea.Cmd {
+	return func() tea.Msg {
+		dir, err := os.Open(path)
+		if err != nil {
+			return dirLoadedMsg{load: load, path: path, err: err}
 		}
+		return readDirectoryPage(load, path, dir, showHidden)()
 	}
+}
+
+// readDirectoryPage reads up to dirPageSize entries. The directory is
+// closed once it has been read to the end or fails.
+func readDirectoryPage(load int, path string, dir *os.File, showHidden bool) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := dir.ReadDir(dirPageSize)
+		msg := dirLoadedMsg{load: load, path: path, dir: dir}
+		if err != nil || len(entries) < dirPageSize {
+			dir.Close()
+			msg.dir = nil
+			if err != io.EOF {
+				msg.err = err
+			}
+		}
 
-	return patternIdx == len(pattern)
+		for _, entry := range entries {
+			// Skip hidden files unless they are toggled on
+			if strings.HasPrefix(entry.Name(), ".") && !showHidden {
+				continue
+			}
+
+			entryPath := filepath.Join(path, entry.Name())
+			msg.entries = append(msg.entries, fileEntry{
+				Name:  entry.Name(),
+				Path:  entryPath,
+				IsDir: isDir(entryPath, entry),
+			})
+		}
+		return msg
+	}
 }
 
+// handleDirLoaded adds a page of entries to the listing, keeping the
+// highlighted entry and the search filter.
+func (m *model) handleDirLoaded(msg dirLoadedMsg) {
+	if msg.load != m.dirLoadID {
+		// Stale result from a directory the user already left
+		if msg.dir != nil {
+			msg.dir.Close()
+		}
+		return
+	}
+
+	m.dirLoading = false
+	m.dirMore = msg.dir
+	m.dirErr = msg.err
+
+	selected := ""
+	if m.cursor < len(m.filteredFiles) {
+		selected = m.filteredFiles[m.cursor].Path
+	}
+
+	// Directories first, then files, each sorted by name
+	files := append(append([]fileEntry{}, m.files...), msg.entries...)
+	listed := files
+	if len(listed) > 0 && listed[0].Name == ".." {
+		listed = listed[1:]
+	}
+	sort.SliceStable(listed, func(i, j int) bool {
+		if listed[i].IsDir != listed[j].IsDir {
+			return listed[i].IsDir
+		}
+		return listed[i].Name < listed[j].Name
+	})
+	m.files = files
+
+	if m.searchInput.Value() != "" {
+		m.filterFiles()
+	} else {
+		m.filteredFiles = m.files
+	}
+	for i, entry := range m.filteredFiles {
+		if entry.Path == selected {
+			m.cursor = i
+			break
+		}
+	}
+	m.updateViewport()
+}
+
+// showRecent lists launched directories by frecency instead of the
+// current directory's contents. Directories that no longer exist are hidden.
+func (m *model) showRecent() {
+	m.cancelDirectoryLoad()
+	history, err := loadHistory()
+	if err != nil {
+		m.err = err
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	m.recentDirs = nil
+	m.files = []fileEntry{}
+	for _, visit := range rankDirectories(history.Directories, time.Now()) {
+		if info, err := os.Stat(visit.Path); err != nil || !info.IsDir() {
+			continue
+		}
+		name := visit.Path
+		if strings.HasPrefix(name, homeDir) {
+			name = "~" + strings.TrimPrefix(name, homeDir)
+		}
+		m.recentDirs = append(m.recentDirs, visit)
+		m.files = append(m.files, fileEntry{
+			Name:  name,
+			Path:  visit.Path,
+			IsDir: true,
+		})
+	}
+
+	m.browseMode = browseRecent
+	m.filteredFiles = m.files
+	m.cursor = 0
+	m.viewportStart = 0
+	m.searching = false
+	m.searchInput.Blur()
+	m.searchInput.SetValue("")
+	m.updateViewport()
+}
+
+// filterFiles fuzzy matches the search query against the listing, best
+// matches first. Entries with equal scores go shortest name first, then in
+// listing order.
 func (m *model) filterFiles() {
 	query := m.searchInput.Value()
 	if query == "" {
 		m.filteredFiles = m.files
 	} else {
-		m.filteredFiles = []fileEntry{}
+		type scored struct {
+			entry fileEntry
+			score int
+		}
+		var matches []scored
 		for _, file := range m.files {
-			if fuzzyMatch(query, file.Name) {
-				m.filteredFiles = append(m.filteredFiles, file)
+			if score, positions, ok := fuzzyScore(query, file.Name); ok {
+				file.Matches = positions
+				matches = append(matches, scored{file, score})
+			}
+		}
+		sort.SliceStable(matches, func(i, j int) bool {
+			if matches[i].score != matches[j].score {
+				return matches[i].score > matches[j].score
 			}
+			return len(matches[i].entry.Name) < len(matches[j].entry.Name)
+		})
+
+		m.filteredFiles = make([]fileEntry, len(matches))
+		for i, match := range matches {
+			m.filteredFiles[i] = match.entry
 		}
 	}
 
@@ -110,6 +274,18 @@ func (m *model) updateViewport() {
 	if m.creatingNewDir || m.searching {
 		availableHeight -= 3 // Extra lines for input
 	}
+	if m.addingBookmark {
+		availableHeight -= 4 // Extra lines for target and alias
+	}
+	if m.enteringPath {
+		availableHeight -= 4 + min(len(m.pathCompletions), maxPathCompletions+1) // Input, status and completions
+	}
+	if m.browseMode == browseFiles && m.dirErr != nil {
+		availableHeight-- // Read error under the current directory
+	}
+	if m.browseMode == browseFiles && m.dirMore != nil {
+		availableHeight-- // "show more" line under the listing
+	}
 	if availableHeight < 5 {
 		availableHeight = 5 // Minimum visible items
 	}
@@ -139,17 +315,25 @@ func (m *model) updateViewport() {
 }
 
 func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Directory browsing mode (when focusIndex == 0)
-	if m.focusIndex == 0 {
+	// Directory browsing mode (when focusIndex == focusDirectory)
+	if m.focusIndex == focusDirectory {
+		if m.enteringPath {
+			return m.updatePathInput(msg)
+		}
+		if m.addingBookmark {
+			return m.updateBookmarkInput(msg)
+		}
+
 		// Handle search mode
 		if m.searching {
 			switch msg.String() {
 			case "enter":
 				// Navigate INTO the directory (stay in browser to continue exploring)
+				var cmd tea.Cmd
 				if m.cursor < len(m.filteredFiles) {
 					entry := m.filteredFiles[m.cursor]
 					if entry.IsDir {
-						m.loadDirectory(entry.Path)
+						cmd = m.loadDirectory(entry.Path)
 					}
 				}
 				// Exit search mode but stay in directory browser
@@ -157,24 +341,25 @@ func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.searchInput.Blur()
 				m.searchInput.SetValue("")
 				m.updateViewport() // Restore viewport to full size
-				return m, nil
+				return m, cmd
 			case "tab":
 				// SELECT this as final directory choice and move to next field
+				var cmd tea.Cmd
 				if m.cursor < len(m.filteredFiles) {
 					entry := m.filteredFiles[m.cursor]
 					if entry.IsDir {
-						m.loadDirectory(entry.Path)
+						cmd = m.loadDirectory(entry.Path)
 					}
 				}
 				m.searching = false
 				m.searchInput.Blur()
 				m.searchInput.SetValue("")
-				m.focusIndex = 1
+				m.focusIndex = focusSession
 				m.inputs[0].Focus()
 				m.inputs[0].PromptStyle = focusedStyle
 				m.inputs[0].TextStyle = focusedStyle
 				m.updateViewport() // Restore viewport to full size
-				return m, nil
+				return m, cmd
 			case "esc":
 				m.searching = false
 				m.searchInput.Blur()
@@ -209,15 +394,15 @@ func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					err := os.MkdirAll(fullPath, 0755)
 					if err == nil {
 						// Create new directory and load it
-						m.loadDirectory(fullPath)
+						cmd := m.loadDirectory(fullPath)
 						m.creatingNewDir = false
 						m.newDirInput.Blur()
 						m.newDirInput.SetValue("")
-						m.focusIndex = 1
+						m.focusIndex = focusSession
 						m.inputs[0].Focus()
 						m.inputs[0].PromptStyle = focusedStyle
 						m.inputs[0].TextStyle = focusedStyle
-						return m, nil
+						return m, cmd
 					}
 				}
 				return m, nil
@@ -234,6 +419,7 @@ func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		} else {
 			// Normal directory navigation
+			m.statusMessage = statusMsg{}
 			switch msg.String() {
 			case "ctrl+c":
 				return m, tea.Quit
@@ -241,24 +427,25 @@ func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 			case "enter", "tab":
 				// Select highlighted directory and load its contents
+				var cmd tea.Cmd
 				if m.cursor < len(m.filteredFiles) {
 					entry := m.filteredFiles[m.cursor]
 					if entry.IsDir {
-						m.loadDirectory(entry.Path)
+						cmd = m.loadDirectory(entry.Path)
 					}
 				}
 				// Move to next field
-				m.focusIndex = 1
+				m.focusIndex = focusSession
 				m.inputs[0].Focus()
 				m.inputs[0].PromptStyle = focusedStyle
 				m.inputs[0].TextStyle = focusedStyle
-				return m, nil
+				return m, cmd
 			case "right":
 				// Navigate INTO selected directory
 				if m.cursor < len(m.filteredFiles) {
 					entry := m.filteredFiles[m.cursor]
 					if entry.IsDir {
-						m.loadDirectory(entry.Path)
+						return m, m.loadDirectory(entry.Path)
 					}
 				}
 				return m, nil
@@ -266,9 +453,59 @@ func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				// Navigate UP to parent directory
 				parent := filepath.Dir(m.directory)
 				if parent != m.directory {
-					m.loadDirectory(parent)
+					return m, m.loadDirectory(parent)
+				}
+				return m, nil
+			case "r":
+				// Toggle between recent projects and the filesystem
+				if m.browseMode == browseRecent {
+					return m, m.loadDirectory(m.directory)
 				}
+				m.showRecent()
 				return m, nil
+			case "p":
+				// Toggle between discovered projects and the filesystem
+				if m.browseMode == browseProjects {
+					return m, m.loadDirectory(m.directory)
+				}
+				return m, m.showProjects()
+			case "b":
+				return m, m.startBookmark()
+			case "B":
+				// Toggle between bookmarks and the filesystem
+				if m.browseMode == browseBookmarks {
+					return m, m.loadDirectory(m.directory)
+				}
+				m.showBookmarks()
+				return m, nil
+			case "x":
+				// Remove the highlighted bookmark
+				if m.browseMode == browseBookmarks && m.cursor < len(m.filteredFiles) {
+					bookmarks := removeBookmark(m.bookmarks, m.filteredFiles[m.cursor].Path)
+					if err := saveBookmarks(bookmarks); err != nil {
+						m.statusMessage = statusMsg{text: err.Error(), isError: true}
+						return m, nil
+					}
+					m.showBookmarks()
+				}
+				return m, nil
+			case ".":
+				// Toggle hidden entries
+				m.showHidden = !m.showHidden
+				if m.browseMode == browseFiles {
+					return m, m.loadDirectory(m.directory)
+				}
+				return m, nil
+			case "m":
+				// Read the next page of a large directory
+				return m, m.loadMoreEntries()
+			case "g":
+				// Type or paste a path
+				m.enteringPath = true
+				m.pathInput.SetValue("")
+				m.pathCompletions = nil
+				m.updateViewport() // Recalculate viewport with reduced space
+				return m, m.pathInput.Focus()
 			case "n":
 				m.creatingNewDir = true
 				m.newDirInput.Focus()
@@ -299,25 +536,21 @@ func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Rest of the form (session name, terminal, agent, button)
 
 	// When session input is focused, block all navigation except esc
-	if m.focusIndex == 1 && m.inputs[0].Focused() {
+	if m.focusIndex == focusSession && m.inputs[0].Focused() {
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
 		case "esc":
 			// Blur the input, go back to directory browser
-			m.focusIndex = 0
+			m.focusIndex = focusDirectory
 			m.inputs[0].Blur()
 			m.inputs[0].PromptStyle = noStyle
 			m.inputs[0].TextStyle = noStyle
-			m.loadDirectory(m.directory)
-			return m, nil
+			return m, m.loadDirectory(m.directory)
 		case "enter":
-			// Move to next field (terminal selection)
-			m.inputs[0].Blur()
-			m.inputs[0].PromptStyle = noStyle
-			m.inputs[0].TextStyle = noStyle
-			m.focusIndex = 2
-			return m, nil
+			// Move to next field (worktree branch)
+			m.focusIndex = focusWorktree
+			return m, m.focusInputs()
 		default:
 			// Allow only text input, block all navigation
 			var cmd tea.Cmd
@@ -326,25 +559,48 @@ func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// The worktree input works the same way
+	if m.focusIndex == focusWorktree && m.inputs[1].Focused() {
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.focusIndex = focusSession
+			return m, m.focusInputs()
+		case "enter":
+			// Name the session after a newly entered branch
+			branch := strings.TrimSpace(m.inputs[1].Value())
+			if branch != "" && branch != m.worktreeBranch {
+				m.inputs[0].SetValue(branchSessionName(branch))
+			}
+			m.worktreeBranch = branch
+			m.focusIndex = focusTerminal
+			return m, m.focusInputs()
+		default:
+			var cmd tea.Cmd
+			m.inputs[1], cmd = m.inputs[1].Update(msg)
+			return m, cmd
+		}
+	}
+
 	switch msg.String() {
 	case "ctrl+c":
 		return m, tea.Quit
 	case "esc":
-		if m.focusIndex > 0 {
+		if m.focusIndex > focusDirectory {
 			// Go back to directory browser and reload current directory
-			m.focusIndex = 0
+			m.focusIndex = focusDirectory
 			m.inputs[0].Blur()
 			m.inputs[0].PromptStyle = noStyle
 			m.inputs[0].TextStyle = noStyle
 			// Reload the directory to reset browser state
-			m.loadDirectory(m.directory)
-			return m, nil
+			return m, m.loadDirectory(m.directory)
 		}
 		return m, tea.Quit
 
 	case "c":
 		// Open custom commands list (only when not in directory browser)
-		if m.focusIndex > 0 {
+		if m.focusIndex > focusDirectory {
 			m.currentState = stateCommands
 			// Update list height to fit terminal
 			m.commandsList.SetSize(m.width-10, m.height-15)
@@ -353,7 +609,7 @@ func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "tab", "shift+tab":
 		s := msg.String()
-		maxIndex := len(m.inputs) + 2
+		maxIndex := focusLaunch
 
 		if s == "shift+tab" {
 			m.focusIndex--
@@ -362,28 +618,15 @@ func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 		if m.focusIndex > maxIndex {
-			m.focusIndex = 0
+			m.focusIndex = focusDirectory
 		} else if m.focusIndex < 0 {
 			m.focusIndex = maxIndex
 		}
 
-		cmds := make([]tea.Cmd, len(m.inputs))
-		for i := 0; i <= len(m.inputs)-1; i++ {
-			if i == m.focusIndex {
-				cmds[i] = m.inputs[i].Focus()
-				m.inputs[i].PromptStyle = focusedStyle
-				m.inputs[i].TextStyle = focusedStyle
-			} else {
-				m.inputs[i].Blur()
-				m.inputs[i].PromptStyle = noStyle
-				m.inputs[i].TextStyle = noStyle
-			}
-		}
-
-		return m, tea.Batch(cmds...)
+		return m, m.focusInputs()
 
 	case "left", "right":
-		if m.focusIndex == 2 {
+		if m.focusIndex == focusTerminal {
 			if msg.String() == "right" {
 				m.terminalCursor++
 				if m.terminalCursor >= len(m.terminalOptions) {
@@ -395,7 +638,7 @@ func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					m.terminalCursor = len(m.terminalOptions) - 1
 				}
 			}
-		} else if m.focusIndex == 3 {
+		} else if m.focusIndex == focusAgent {
 			if msg.String() == "right" {
 				m.agentCursor++
 				if m.agentCursor >= len(m.agentOptions) {
@@ -407,35 +650,59 @@ func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					m.agentCursor = len(m.agentOptions) - 1
 				}
 			}
+		} else if m.focusIndex == focusLayout {
+			if msg.String() == "right" {
+				m.layoutCursor++
+				if m.layoutCursor >= len(m.layouts) {
+					m.layoutCursor = 0
+				}
+			} else {
+				m.layoutCursor--
+				if m.layoutCursor < 0 {
+					m.layoutCursor = len(m.layouts) - 1
+				}
+			}
+		}
+		return m, nil
+
+	case " ":
+		// Pick several agents to run side by side
+		if m.focusIndex == focusAgent {
+			m.toggleAgent()
 		}
 		return m, nil
 
 	case "enter":
-		if m.focusIndex == 4 {
+		if m.focusIndex == focusLaunch {
 			m.currentState = statePreview
 			return m, nil
 		}
 
 		m.focusIndex++
-		if m.focusIndex > 4 {
-			m.focusIndex = 0
+		if m.focusIndex > focusLaunch {
+			m.focusIndex = focusDirectory
 		}
 
-		cmds := make([]tea.Cmd, len(m.inputs))
-		for i := 0; i <= len(m.inputs)-1; i++ {
-			if i == m.focusIndex {
-				cmds[i] = m.inputs[i].Focus()
-				m.inputs[i].PromptStyle = focusedStyle
-				m.inputs[i].TextStyle = focusedStyle
-			} else {
-				m.inputs[i].Blur()
-				m.inputs[i].PromptStyle = noStyle
-				m.inputs[i].TextStyle = noStyle
-			}
-		}
-		return m, tea.Batch(cmds...)
+		return m, m.focusInputs()
 	}
 
 	// Note: Session input update is handled in the focused block above
 	return m, nil
 }
+
+// focusInputs focuses the text input under focusIndex and blurs the others.
+func (m *model) focusInputs() tea.Cmd {
+	cmds := make([]tea.Cmd, len(m.inputs))
+	for i := range m.inputs {
+		if focusSession+i == m.focusIndex {
+			cmds[i] = m.inputs[i].Focus()
+			m.inputs[i].PromptStyle = focusedStyle
+			m.inputs[i].TextStyle = focusedStyle
+		} else {
+			m.inputs[i].Blur()
+			m.inputs[i].PromptStyle = noStyle
+			m.inputs[i].TextStyle = noStyle
+		}
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Files and directories that mark a directory as a project, with the tag
+// shown next to it in the projects list.
+var projectMarkers = []struct {
+	file string
+	tag  string
+}{
+	{".git", "git"},
+	{"go.mod", "go"},
+	{"package.json", "node"},
+	{"Cargo.toml", "rust"},
+	{"pyproject.toml", "python"},
+	{"requirements.txt", "python"},
+	{"Gemfile", "ruby"},
+	{"pom.xml", "java"},
+	{"build.gradle", "java"},
+	{"composer.json", "php"},
+	{"mix.exs", "elixir"},
+	{projectFileName, "vinw"},
+}
+
+var (
+	defaultProjectRoots = []string{"~/code", "~/work", "~/projects", "~/src", "~/dev"}
+	defaultScanSkip     = []string{"node_modules", "vendor", "target", "dist", "build", "venv"}
+)
+
+const defaultScanDepth = 3
+
+// ProjectDir is a directory found by the project scan.
+type ProjectDir struct {
+	Path string   `json:"path"`
+	Tags []string `json:"tags"`
+}
+
+// ProjectCache stores the last scan in projects.json so the projects list
+// can be shown before a new scan finishes.
+type ProjectCache struct {
+	ScannedAt time.Time    `json:"scanned_at"`
+	Projects  []ProjectDir `json:"projects"`
+}
+
+// projectsFoundMsg carries a batch of scan results. scan identifies the
+// scan that produced it.
+type projectsFoundMsg struct {
+	scan     int
+	projects []ProjectDir
+	done     bool
+}
+
+// scanRoots returns the configured project roots, or the default roots
+// that exist.
+func scanRoots(config Config) []string {
+	roots := config.ProjectRoots
+	if len(roots) == 0 {
+		roots = defaultProjectRoots
+	}
+
+	var out []string
+	for _, root := range roots {
+		dir := expandPath(root)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			out = append(out, dir)
+		}
+	}
+	return out
+}
+
+// scanProjects walks the roots in the background and sends every project
+// directory it finds. The channel is closed when the walk is complete.
+//
+// The walk does not descend into projects, hidden directories or
+// directories named in skip, and stops depth levels below each root.
+func scanProjects(roots []string, depth int, skip []string) <-chan ProjectDir {
+	ch := make(chan ProjectDir)
+
+	var walk func(dir string, level int)
+	walk = func(dir string, level int) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+
+		names := map[string]bool{}
+		for _, entry := range entries {
+			names[entry.Name()] = true
+		}
+		var tags []string
+		for _, marker := range projectMarkers {
+			if names[marker.file] && !contains(tags, marker.tag) {
+				tags = append(tags, marker.tag)
+			}
+		}
+		if len(tags) > 0 {
+			ch <- ProjectDir{Path: dir, Tags: tags}
+			return
+		}
+
+		if level >= depth {
+			return
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() || strings.HasPrefix(name, ".") || contains(skip, name) {
+				continue
+			}
+			walk(filepath.Join(dir, name), level+1)
+		}
+	}
+
+	go func() {
+		defer close(ch)
+		for _, root := range roots {
+			walk(root, 0)
+		}
+	}()
+	return ch
+}
+
+// waitForProjects reads the next batch of scan results. Results that are
+// already waiting are sent together so the list is not redrawn per project.
+func waitForProjects(scan int, ch <-chan ProjectDir) tea.Cmd {
+	return func() tea.Msg {
+		project, ok := <-ch
+		if !ok {
+			return projectsFoundMsg{scan: scan, done: true}
+		}
+
+		batch := []ProjectDir{project}
+		for len(batch) < 100 {
+			select {
+			case project, ok := <-ch:
+				if !ok {
+					return projectsFoundMsg{scan: scan, projects: batch, done: true}
+				}
+				batch = append(batch, project)
+			default:
+				return projectsFoundMsg{scan: scan, projects: batch}
+			}
+		}
+		return projectsFoundMsg{scan: scan, projects: batch}
+	}
+}
+
+func projectCacheFile() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "projects.json"), nil
+}
+
+// loadProjectCache loads the last scan from ~/.vinw-workspace/projects.json
+func loadProjectCache() (ProjectCache, error) {
+	var cache ProjectCache
+
+	file, err := projectCacheFile()
+	if err != nil {
+		return cache, err
+	}
+
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return cache, err
+	}
+
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return ProjectCache{}, fmt.Errorf("invalid projects.json: %w", err)
+	}
+	return cache, nil
+}
+
+func saveProjectCache(cache ProjectCache) error {
+	file, err := projectCacheFile()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, data, 0644)
+}
+
+// showProjects switches the directory chooser to discovered projects. The
+// cached list is shown right away and a new scan is started unless one is
+// already running.
+func (m *model) showProjects() tea.Cmd {
+	m.cancelDirectoryLoad()
+	m.browseMode = browseProjects
+	m.searching = false
+	m.searchInput.Blur()
+	m.searchInput.SetValue("")
+	m.cursor = 0
+	m.viewportStart = 0
+
+	if m.projects == nil {
+		cache, err := loadProjectCache()
+		if err != nil {
+			m.err = err
+		}
+		m.projects = cache.Projects
+	}
+	m.setProjectEntries()
+
+	if m.scanningProjects {
+		return nil
+	}
+	roots := scanRoots(m.config)
+	if len(roots) == 0 {
+		return nil
+	}
+
+	depth := m.config.ScanDepth
+	if depth <= 0 {
+		depth = defaultScanDepth
+	}
+	skip := m.config.ScanSkip
+	if len(skip) == 0 {
+		skip = defaultScanSkip
+	}
+
+	m.projectScanID++
+	m.scanningProjects = true
+	m.scannedProjects = nil
+	m.projectScan = scanProjects(roots, depth, skip)
+	return waitForProjects(m.projectScanID, m.projectScan)
+}
+
+// handleProjectsFound merges a batch of scan results. The finished scan
+// replaces the cached list so removed projects disappear.
+func (m *model) handleProjectsFound(msg projectsFoundMsg) tea.Cmd {
+	if msg.scan != m.projectScanID {
+		return nil
+	}
+
+	m.scannedProjects = append(m.scannedProjects, msg.projects...)
+	for _, found := range msg.projects {
+		known := false
+		for _, p := range m.projects {
+			if p.Path == found.Path {
+				known = true
+				break
+			}
+		}
+		if !known {
+			m.projects = append(m.projects, found)
+		}
+	}
+
+	if msg.done {
+		m.scanningProjects = false
+		m.projects = m.scannedProjects
+		if err := saveProjectCache(ProjectCache{ScannedAt: time.Now(), Projects: m.projects}); err != nil {
+			m.err = err
+		}
+	}
+
+	if m.browseMode == browseProjects {
+		m.setProjectEntries()
+	}
+	if msg.done {
+		return nil
+	}
+	return waitForProjects(msg.scan, m.projectScan)
+}
+
+// setProjectEntries lists the known projects in the directory chooser,
+// keeping the search filter and the highlighted project.
+func (m *model) setProjectEntries() {
+	sort.Slice(m.projects, func(i, j int) bool { return m.projects[i].Path < m.projects[j].Path })
+
+	selected := ""
+	if m.cursor < len(m.filteredFiles) {
+		selected = m.filteredFiles[m.cursor].Path
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	m.files = []fileEntry{}
+	for _, p := range m.projects {
+		name := p.Path
+		if strings.HasPrefix(name, homeDir) {
+			name = "~" + strings.TrimPrefix(name, homeDir)
+		}
+		m.files = append(m.files, fileEntry{
+			Name:  name,
+			Path:  p.Path,
+			IsDir: true,
+		})
+	}
+
+	m.filterFiles()
+	for i, entry := range m.filteredFiles {
+		if entry.Path == selected {
+			m.cursor = i
+			break
+		}
+	}
+	m.updateViewport()
+}
+
+// projectTags returns the marker tags of a discovered project.
+func (m model) projectTags(path string) []string {
+	for _, p := range m.projects {
+		if p.Path == path {
+			return p.Tags
+		}
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+// Export formats
+const (
+	exportShell      = "sh"
+	exportTmuxinator = "tmuxinator"
+)
+
+var exportFormats = []string{exportShell, exportTmuxinator}
+
+// Nominal window size of exported tmux layouts; tmux scales a layout to
+// the real window when it is applied.
+const exportWidth, exportHeight = 200, 50
+
+// exportFormat picks the format for an export file from its extension.
+func exportFormat(path string) string {
+	switch filepath.Ext(path) {
+	case ".yml", ".yaml":
+		return exportTmuxinator
+	}
+	return exportShell
+}
+
+// exportWorkspace renders a workspace in the given format. worktree is the
+// planned worktree when the workspace is launched in one, else nil.
+func exportWorkspace(format string, spec workspaceSpec, worktree *worktreePlan) (string, error) {
+	switch format {
+	case exportShell:
+		return exportScript(spec, worktree)
+	case exportTmuxinator:
+		return exportTmuxinatorFile(spec, worktree)
+	}
+	return "", fmt.Errorf("unknown export format %q (options: %s)", format, strings.Join(exportFormats, ", "))
+}
+
+// exportScript renders a workspace as a POSIX shell script that runs the
+// steps of its launch plan, keeping pane IDs in variables.
+func exportScript(spec workspaceSpec, worktree *worktreePlan) (string, error) {
+	plan, err := buildLaunchPlan(spec)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&b, "# Workspace '%s' exported by vinw-workspace (layout %s)\n", spec.Session, spec.Layout.Name)
+	b.WriteString("set -e\n\n")
+
+	fmt.Fprintf(&b, "if tmux has-session -t %s 2>/dev/null; then\n", shellQuote("="+spec.Session))
+	fmt.Fprintf(&b, "\techo %s >&2\n\texit 1\nfi\n", shellQuote("session '"+spec.Session+"' already exists"))
+
+	if worktree != nil && worktree.Add != nil {
+		fmt.Fprintf(&b, "\n[ -d %s ] || %s\n", shellQuote(worktree.Path), worktree.addCommand())
+	}
+
+	// A blank line between the layout, its markers, its commands, each
+	// window and the focus
+	group, inWindows := "", false
+	for _, step := range plan.Steps {
+		verb, _, _ := strings.Cut(step.Desc, " ")
+		switch {
+		case strings.HasPrefix(step.Desc, "create window"):
+			inWindows = true
+			b.WriteString("\n")
+		case verb == "focus" || !inWindows && verb != group && verb != "split":
+			b.WriteString("\n")
+		}
+		group = verb
+		b.WriteString(step.script() + "\n")
+	}
+
+	fmt.Fprintf(&b, "\nif [ -n \"$TMUX\" ]; then\n\t%s\nelse\n\t%s\nfi\n",
+		scriptCommand([]string{"switch-client", "-t", spec.Session}),
+		scriptCommand([]string{"attach-session", "-t", spec.Session}))
+	return b.String(), nil
+}
+
+// tmuxinatorWindow is the main window of an exported tmuxinator project.
+type tmuxinatorWindow struct {
+	Layout string `yaml:"layout"`
+	Panes  []any  `yaml:"panes"`
+}
+
+// tmuxinatorProject is an exported tmuxinator project. Windows are
+// single-key maps from window name to definition.
+type tmuxinatorProject struct {
+	Name                string           `yaml:"name"`
+	Root                string           `yaml:"root"`
+	OnProjectFirstStart string           `yaml:"on_project_first_start,omitempty"`
+	Windows             []map[string]any `yaml:"windows"`
+}
+
+// exportTmuxinatorFile renders a workspace as a tmuxinator project. The
+// layout becomes a tmux layout string; tmuxinator types every command, so
+// the respawn pane start, pane roles and ready checks are not carried over.
+func exportTmuxinatorFile(spec workspaceSpec, worktree *worktreePlan) (string, error) {
+	root, err := parseWindowgram(spec.Layout.Windowgram)
+	if err != nil {
+		return "", fmt.Errorf("invalid layout '%s': %w", spec.Layout.Name, err)
+	}
+	starts := paneStarts(root, spec)
+	_, extraAgents := placeAgents(root, spec.Agents)
+
+	project := tmuxinatorProject{Name: spec.Session, Root: spec.Dir}
+	if worktree != nil && worktree.Add != nil {
+		project.OnProjectFirstStart = worktree.addCommand()
+	}
+
+	// tmuxinator opens every pane in the root, so panes elsewhere cd first
+	command := func(start paneStart) any {
+		if start.Command == "" {
+			return nil
+		}
+		if start.Dir != spec.Dir {
+			return "cd " + shellQuote(start.Dir) + " && " + start.Command
+		}
+		return start.Command
+	}
+
+	layoutWindow := tmuxinatorWindow{Layout: tmuxLayout(root, exportWidth, exportHeight)}
+	for _, pane := range root.panes() {
+		layoutWindow.Panes = append(layoutWindow.Panes, command(starts[pane]))
+	}
+	project.Windows = append(project.Windows, map[string]any{spec.Layout.Name: layoutWindow})
+
+	for _, agent := range extraAgents {
+		project.Windows = append(project.Windows, map[string]any{agent.Name: command(agentStart(agent, spec.Dir))})
+	}
+	for _, window := range spec.Windows {
+		start := paneStart{Dir: window.workDir(spec.Dir), Command: window.Command}
+		project.Windows = append(project.Windows, map[string]any{window.Name: command(start)})
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Workspace '%s' exported by vinw-workspace (layout %s)\n", spec.Session, spec.Layout.Name)
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(project); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// tmuxLayout returns the tmux layout string of a parsed windowgram at the
+// given window size, for select-layout. Panes are numbered in the order of
+// root.panes().
+func tmuxLayout(root *layoutNode, width, height int) string {
+	var b strings.Builder
+	id := 0
+
+	var write func(n *layoutNode, w, h, x, y int)
+	write = func(n *layoutNode, w, h, x, y int) {
+		fmt.Fprintf(&b, "%dx%d,%d,%d", w, h, x, y)
+		if len(n.Children) == 0 {
+			fmt.Fprintf(&b, ",%d", id)
+			id++
+			return
+		}
+
+		opening, closing, extent, pos := "{", "}", w, x
+		if n.Vertical {
+			opening, closing, extent, pos = "[", "]", h, y
+		}
+		total := 0
+		for _, child := range n.Children {
+			total += child.Size
+		}
+
+		// Borders between siblings take one cell each
+		room := extent - (len(n.Children) - 1)
+		used := 0
+		b.WriteString(opening)
+		for i, child := range n.Children {
+			size := room * child.Size / total
+			if i == len(n.Children)-1 {
+				size = room - used
+			}
+			used += size
+			if i > 0 {
+				b.WriteString(",")
+			}
+			if n.Vertical {
+				write(child, w, size, x, pos)
+			} else {
+				write(child, size, h, pos, y)
+			}
+			pos += size + 1
+		}
+		b.WriteString(closing)
+	}
+	write(root, width, height, 0, 0)
+
+	layout := b.String()
+	return fmt.Sprintf("%04x,%s", layoutChecksum(layout), layout)
+}
+
+// layoutChecksum is tmux's checksum of a layout string.
+func layoutChecksum(layout string) uint16 {
+	var csum uint16
+	for i := 0; i < len(layout); i++ {
+		csum = (csum >> 1) + ((csum & 1) << 15)
+		csum += uint16(layout[i])
+	}
+	return csum
+}
+
+// startExport asks where to write the export, suggesting a script named
+// after the session in the workspace directory.
+func (m model) startExport() (tea.Model, tea.Cmd) {
+	m.exporting = true
+	m.statusMessage = statusMsg{}
+	m.exportPathInput.SetValue(filepath.Join(m.directory, branchSessionName(m.inputs[0].Value())+"-workspace.sh"))
+	m.exportPathInput.CursorEnd()
+	return m, m.exportPathInput.Focus()
+}
+
+// updateExportInput handles keys while the export path is typed
+func (m model) updateExportInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.exporting = false
+		m.exportPathInput.Blur()
+		return m, nil
+
+	case "enter":
+		path := expandPath(strings.TrimSpace(m.exportPathInput.Value()))
+		if path == "" {
+			return m, nil
+		}
+		// Like imports, exports never replace a file; the path stays open
+		// for another name
+		if _, err := os.Stat(path); err == nil {
+			m.statusMessage = statusMsg{text: path + " exists; choose another path", isError: true}
+			return m, nil
+		}
+		m.exporting = false
+		m.exportPathInput.Blur()
+
+		spec, worktree, err := m.formSpec()
+		var out string
+		if err == nil {
+			out, err = exportWorkspace(exportFormat(path), spec, worktree)
+		}
+		if err == nil {
+			// Scripts are made executable
+			perm := os.FileMode(0644)
+			if exportFormat(path) == exportShell {
+				perm = 0755
+			}
+			err = os.WriteFile(path, []byte(out), perm)
+		}
+		if err != nil {
+			m.statusMessage = statusMsg{text: err.Error(), isError: true}
+			return m, nil
+		}
+		m.statusMessage = statusMsg{text: fmt.Sprintf("✓ Exported to %s", path)}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.exportPathInput, cmd = m.exportPathInput.Update(msg)
+	return m, cmd
+}
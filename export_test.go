@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestExportKeepsExistingFile(t *testing.T) {
+	cliEnv(t)
+	dir := t.TempDir()
+	existing := filepath.Join(dir, ".tmuxinator.yml")
+	if err := os.WriteFile(existing, []byte("name: mine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := initialModel()
+	m.currentState = statePreview
+	m.directory = dir
+	m.inputs[0].SetValue("api")
+	export := func(m model, path string) model {
+		next, _ := m.startExport()
+		m = next.(model)
+		m.exportPathInput.SetValue(path)
+		next, _ = m.updateExportInput(tea.KeyMsg{Type: tea.KeyEnter})
+		return next.(model)
+	}
+
+	m = export(m, existing)
+	if data, _ := os.ReadFile(existing); string(data) != "name: mine\n" {
+		t.Errorf("export replaced %s:\n%s", existing, data)
+	}
+	if !m.exporting || !strings.Contains(m.statusMessage.text, "exists; choose another path") {
+		t.Errorf("exporting = %v, status = %q; want the path asked for again", m.exporting, m.statusMessage.text)
+	}
+
+	script := filepath.Join(dir, "api.sh")
+	m = export(m, script)
+	if _, err := os.Stat(script); err != nil || m.exporting {
+		t.Errorf("export to a new path: %v, exporting = %v", err, m.exporting)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Fuzzy match scoring. Every matched character scores, and characters that
+// continue a run or start a word score more, so "vw" ranks vinw-workspace
+// above names where the letters are scattered.
+const (
+	scoreMatch       = 16
+	bonusConsecutive = 24
+	bonusPrefix      = 24 // first character of the text
+	bonusBoundary    = 20 // after a separator or at a camelCase hump
+	penaltyGap       = 1  // per skipped character between matches
+	penaltyLeading   = 1  // per character before the first match, up to maxLeading
+	maxLeading       = 8
+)
+
+var matchHighlightStyle = lipgloss.NewStyle().Foreground(pinkColor).Bold(true).Underline(true)
+
+// fuzzyScore matches pattern against text as a case-insensitive subsequence
+// and returns the best score with the rune positions that produced it.
+func fuzzyScore(pattern, text string) (int, []int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(text)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+	if len(p) > len(t) {
+		return 0, nil, false
+	}
+
+	lower := make([]rune, len(t))
+	for i, r := range t {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// best[j][i] is the best score for p[:j+1] with p[j] matched at t[i];
+	// from[j][i] is where p[j-1] was matched for that score
+	const none = -1 << 30
+	best := make([][]int, len(p))
+	from := make([][]int, len(p))
+	for j := range p {
+		best[j] = make([]int, len(t))
+		from[j] = make([]int, len(t))
+		for i := range t {
+			best[j][i] = none
+			if lower[i] != p[j] {
+				continue
+			}
+			charScore := scoreMatch + positionBonus(t, i)
+
+			if j == 0 {
+				best[j][i] = charScore - min(i, maxLeading)*penaltyLeading
+				continue
+			}
+			for k := j - 1; k < i; k++ {
+				if best[j-1][k] == none {
+					continue
+				}
+				score := best[j-1][k] + charScore
+				if k == i-1 {
+					score += bonusConsecutive
+				} else {
+					score -= (i - k - 1) * penaltyGap
+				}
+				if score > best[j][i] {
+					best[j][i] = score
+					from[j][i] = k
+				}
+			}
+		}
+	}
+
+	last := len(p) - 1
+	end := -1
+	for i := range t {
+		if best[last][i] != none && (end < 0 || best[last][i] > best[last][end]) {
+			end = i
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	positions := make([]int, len(p))
+	for j, i := last, end; j >= 0; j-- {
+		positions[j] = i
+		i = from[j][i]
+	}
+	return best[last][end], positions, true
+}
+
+// positionBonus rewards matches at the start of the text or of a word.
+func positionBonus(t []rune, i int) int {
+	if i == 0 {
+		return bonusPrefix
+	}
+	prev, cur := t[i-1], t[i]
+	switch {
+	case strings.ContainsRune("-_./ ~", prev):
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return bonusBoundary
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev) && unicode.IsLetter(cur):
+		return bonusBoundary
+	}
+	return 0
+}
+
+// highlightMatches renders text in style with the matched runes highlighted.
+func highlightMatches(text string, matches []int, style lipgloss.Style) string {
+	if len(matches) == 0 {
+		return style.Render(text)
+	}
+
+	matched := map[int]bool{}
+	for _, i := range matches {
+		matched[i] = true
+	}
+
+	var s strings.Builder
+	var run []rune
+	runMatched := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if runMatched {
+			s.WriteString(matchHighlightStyle.Render(string(run)))
+		} else {
+			s.WriteString(style.Render(string(run)))
+		}
+		run = run[:0]
+	}
+	for i, r := range []rune(text) {
+		if matched[i] != runMatched {
+			flush()
+			runMatched = matched[i]
+		}
+		run = append(run, r)
+	}
+	flush()
+	return s.String()
+}
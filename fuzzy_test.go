@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestFuzzyScoreRanking(t *testing.T) {
+	tests := []struct {
+		name       string
+		pattern    string
+		candidates []string
+		want       string // best ranked candidate
+	}{
+		{"vw finds vinw-workspace", "vw", []string{"dev-tools", "review", "vinw", "vendor-web-assets", "vinw-workspace", "viewer"}, "vinw-workspace"},
+		{"prefix beats scattered", "api", []string{"my-rapid-index", "api-server"}, "api-server"},
+		{"word boundary bonus", "ws", []string{"workstation", "work_station"}, "work_station"},
+		{"camelCase hump", "ws", []string{"windows", "webServer"}, "webServer"},
+		{"case-insensitive", "VW", []string{"vinw", "vinw-workspace"}, "vinw-workspace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			type scored struct {
+				name  string
+				score int
+			}
+			var ranked []scored
+			for _, c := range tt.candidates {
+				if score, _, ok := fuzzyScore(tt.pattern, c); ok {
+					ranked = append(ranked, scored{c, score})
+				}
+			}
+			sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+			if len(ranked) == 0 || ranked[0].name != tt.want {
+				t.Errorf("%q ranks %v, want %s first", tt.pattern, ranked, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuzzyScoreMatches(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		ok            bool
+		positions     []int // rune offsets in text
+	}{
+		{"vw", "vinw-workspace", true, []int{0, 5}},
+		{"VW", "VinwWorkspace", true, []int{0, 4}},
+		{"ñd", "café-ñandú", true, []int{5, 8}},
+		{"Ñ", "año", true, []int{1}},
+		{"ü", "über", true, []int{0}},
+		{"", "anything", true, nil},
+		{"xyz", "vinw-workspace", false, nil},
+		{"wv", "vinw", false, nil},
+		{"longer", "long", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" in "+tt.text, func(t *testing.T) {
+			_, positions, ok := fuzzyScore(tt.pattern, tt.text)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if fmt.Sprint(positions) != fmt.Sprint(tt.positions) {
+				t.Errorf("positions = %v, want %v", positions, tt.positions)
+			}
+		})
+	}
+}
+
+func TestFuzzyScoreIgnoresCase(t *testing.T) {
+	lower, _, _ := fuzzyScore("vw", "vinw-workspace")
+	upper, _, _ := fuzzyScore("VW", "vinw-workspace")
+	if lower != upper {
+		t.Errorf("score of VW = %d, of vw = %d; want the same", upper, lower)
+	}
+}
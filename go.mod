@@ -9,6 +9,7 @@ require (
 	github.com/charmbracelet/glamour v0.10.0
 	github.com/charmbracelet/lipgloss v1.1.1-0.20250404203927-76690c660834
 	github.com/lucasb-eyer/go-colorful v1.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
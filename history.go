@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// maxHistory is how many launched directories are remembered.
+const maxHistory = 200
+
+// DirVisit is a directory that workspaces were launched from.
+type DirVisit struct {
+	Path     string    `json:"path"`
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// DirHistory stores launched directories in history.json
+type DirHistory struct {
+	Directories []DirVisit `json:"directories"`
+}
+
+func historyFile() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "history.json"), nil
+}
+
+// loadHistory loads launched directories from ~/.vinw-workspace/history.json
+func loadHistory() (DirHistory, error) {
+	var history DirHistory
+
+	file, err := historyFile()
+	if err != nil {
+		return history, err
+	}
+
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return history, err
+	}
+
+	if err := json.Unmarshal(data, &history); err != nil {
+		return DirHistory{}, fmt.Errorf("invalid history.json: %w", err)
+	}
+	return history, nil
+}
+
+func saveHistory(history DirHistory) error {
+	file, err := historyFile()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, data, 0644)
+}
+
+// recordDirectory counts a launch from dir. When the history is full the
+// lowest ranked directory is forgotten.
+func recordDirectory(dir string) error {
+	history, err := loadHistory()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	found := false
+	for i := range history.Directories {
+		if history.Directories[i].Path == dir {
+			history.Directories[i].Count++
+			history.Directories[i].LastUsed = now
+			found = true
+			break
+		}
+	}
+	if !found {
+		history.Directories = append(history.Directories, DirVisit{Path: dir, Count: 1, LastUsed: now})
+	}
+
+	if len(history.Directories) > maxHistory {
+		history.Directories = rankDirectories(history.Directories, now)[:maxHistory]
+	}
+	return saveHistory(history)
+}
+
+// frecency scores a directory by how often and how recently it was
+// launched: every launch counts, recent ones count more.
+func frecency(visit DirVisit, now time.Time) float64 {
+	age := now.Sub(visit.LastUsed)
+	weight := 0.25
+	switch {
+	case age < time.Hour:
+		weight = 4
+	case age < 24*time.Hour:
+		weight = 2
+	case age < 7*24*time.Hour:
+		weight = 1
+	case age < 30*24*time.Hour:
+		weight = 0.5
+	}
+	return float64(visit.Count) * weight
+}
+
+// rankDirectories sorts directories by frecency, most relevant first.
+func rankDirectories(visits []DirVisit, now time.Time) []DirVisit {
+	ranked := append([]DirVisit{}, visits...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		si, sj := frecency(ranked[i], now), frecency(ranked[j], now)
+		if si != sj {
+			return si > sj
+		}
+		return ranked[i].LastUsed.After(ranked[j].LastUsed)
+	})
+	return ranked
+}
+
+// timeAgo formats how long ago t was, e.g. "5m ago" or "3d ago".
+func timeAgo(t time.Time, now time.Time) string {
+	age := now.Sub(t)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
@@ -0,0 +1,550 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+)
+
+// Where tmuxinator and tmuxp keep their session files, searched by the
+// import screen.
+var importDirs = []string{"~/.config/tmuxinator", "~/.tmuxinator", "~/.config/tmuxp", "~/.tmuxp"}
+
+// importResult is a tmuxinator or tmuxp session converted to a preset,
+// with everything in it that has no vinw-workspace counterpart.
+type importResult struct {
+	Format   string // "tmuxinator" or "tmuxp"
+	Preset   Preset
+	Unmapped []string
+}
+
+// importer accumulates the conversion of one session file.
+//
+// vinw-workspace builds the main window itself, so panes are mapped by
+// what they run: a pane running a known coding agent selects that agent,
+// and every other pane with a command becomes an extra window. Pre-commands
+// are prefixed to each command.
+type importer struct {
+	agents     []Agent
+	result     importResult
+	mainWindow bool // a window was mapped onto the main window's layout
+}
+
+// importSessionFile converts a tmuxinator or tmuxp file. defaultDir is the
+// workspace directory when the file has no root.
+func importSessionFile(path, defaultDir string, agents []Agent) (importResult, error) {
+	data, err := os.ReadFile(expandPath(path))
+	if err != nil {
+		return importResult{}, err
+	}
+	result, err := importSession(data, defaultDir, agents)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return result, nil
+}
+
+// importSession converts tmuxinator YAML or tmuxp YAML/JSON. JSON is read
+// by the YAML parser too.
+func importSession(data []byte, defaultDir string, agents []Agent) (importResult, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return importResult{}, fmt.Errorf("invalid session file: %w", err)
+	}
+
+	imp := &importer{agents: agents}
+	switch {
+	case doc["session_name"] != nil:
+		imp.tmuxp(doc, defaultDir)
+	case doc["name"] != nil || doc["project_name"] != nil || doc["windows"] != nil || doc["tabs"] != nil:
+		imp.tmuxinator(doc, defaultDir)
+	default:
+		return importResult{}, fmt.Errorf("not a tmuxinator or tmuxp session file")
+	}
+	if imp.mainWindow {
+		// Pick the layout with a pane for every agent
+		if layout, ok := layoutWithAgents(len(splitAgentNames(imp.result.Preset.Agent))); ok {
+			imp.result.Preset.Layout = layout.Name
+		}
+	}
+	return imp.result, nil
+}
+
+// tmuxinator converts a tmuxinator project.
+func (imp *importer) tmuxinator(doc map[string]any, defaultDir string) {
+	imp.result.Format = "tmuxinator"
+	imp.session(firstValue(doc, "name", "project_name"), firstValue(doc, "root", "project_root"), defaultDir)
+	pre := stringList(firstValue(doc, "pre_window", "pre_tab"))
+	imp.unknownKeys("", doc, "name", "project_name", "root", "project_root", "pre_window", "pre_tab", "windows", "tabs")
+
+	for _, item := range anyList(firstValue(doc, "windows", "tabs")) {
+		// Each window is a single-key map from its name to its definition
+		entry, ok := item.(map[string]any)
+		if !ok || len(entry) != 1 {
+			imp.unmappedf("window %v: not a name: definition pair", item)
+			continue
+		}
+		for name, def := range entry {
+			imp.tmuxinatorWindow(name, def, pre)
+		}
+	}
+}
+
+func (imp *importer) tmuxinatorWindow(name string, def any, pre []string) {
+	fields, ok := def.(map[string]any)
+	if !ok {
+		// A command, a list of commands, or nothing for a plain shell
+		imp.window(name, "", nil, pre, [][]string{stringList(def)})
+		return
+	}
+
+	pre = append(append([]string{}, pre...), stringList(fields["pre"])...)
+	imp.unknownKeys("window "+name, fields, "root", "layout", "pre", "panes")
+
+	var panes [][]string
+	for _, pane := range anyList(fields["panes"]) {
+		if named, ok := pane.(map[string]any); ok {
+			// A named pane maps its name to its commands
+			for _, commands := range named {
+				panes = append(panes, stringList(commands))
+			}
+			continue
+		}
+		panes = append(panes, stringList(pane))
+	}
+	imp.window(name, stringValue(fields["root"]), fields["layout"], pre, panes)
+}
+
+// tmuxp converts a tmuxp session.
+func (imp *importer) tmuxp(doc map[string]any, defaultDir string) {
+	imp.result.Format = "tmuxp"
+	imp.session(doc["session_name"], doc["start_directory"], defaultDir)
+	pre := stringList(doc["shell_command_before"])
+	imp.unknownKeys("", doc, "session_name", "start_directory", "shell_command_before", "windows")
+
+	for i, item := range anyList(doc["windows"]) {
+		fields, ok := item.(map[string]any)
+		if !ok {
+			imp.unmappedf("window %d: not a window definition", i+1)
+			continue
+		}
+		name := stringValue(fields["window_name"])
+		if name == "" {
+			name = fmt.Sprintf("window-%d", i+1)
+		}
+
+		windowPre := append(append([]string{}, pre...), stringList(fields["shell_command_before"])...)
+		imp.unknownKeys("window "+name, fields, "window_name", "layout", "start_directory", "shell_command_before", "panes")
+
+		var panes [][]string
+		for _, pane := range anyList(fields["panes"]) {
+			switch pane := pane.(type) {
+			case map[string]any:
+				imp.unknownKeys("window "+name+" pane", pane, "shell_command")
+				panes = append(panes, stringList(pane["shell_command"]))
+			case string:
+				// "blank" and "pane" are tmuxp's names for an empty shell
+				if pane != "blank" && pane != "pane" {
+					panes = append(panes, []string{pane})
+				}
+			default:
+				panes = append(panes, stringList(pane))
+			}
+		}
+		imp.window(name, stringValue(fields["start_directory"]), fields["layout"], windowPre, panes)
+	}
+}
+
+// session fills in the preset's name, session and directory.
+func (imp *importer) session(name, root any, defaultDir string) {
+	preset := &imp.result.Preset
+	preset.Name = stringValue(name)
+	preset.Session = branchSessionName(preset.Name)
+
+	dir := expandPath(stringValue(root))
+	switch {
+	case dir == "":
+		dir = defaultDir
+		imp.unmappedf("no root directory - using %s", defaultDir)
+	case !filepath.IsAbs(dir):
+		dir = filepath.Join(defaultDir, dir)
+	}
+	preset.Dir = filepath.Clean(dir)
+	if info, err := os.Stat(preset.Dir); err != nil || !info.IsDir() {
+		imp.unmappedf("root %s does not exist", preset.Dir)
+	}
+}
+
+// window maps the panes of one tmux window. A pane running an agent picks
+// that agent. The first window of agents and at most one command, in the
+// session directory, becomes the main window, with the command in its
+// terminal pane. Otherwise each pane with a command becomes an extra window.
+func (imp *importer) window(name, dir string, layout any, pre []string, panes [][]string) {
+	var commands [][]string
+	agents := 0
+	for _, pane := range panes {
+		switch {
+		case len(pane) == 0:
+		case len(pane) == 1 && imp.selectAgent(name, pane[0]):
+			agents++
+		default:
+			commands = append(commands, pane)
+		}
+	}
+	join := func(commands []string) string {
+		return strings.Join(append(append([]string{}, pre...), commands...), "; ")
+	}
+
+	if agents > 0 && len(commands) <= 1 && dir == "" && !imp.mainWindow {
+		imp.mainWindow = true
+		if len(commands) == 1 {
+			imp.result.Preset.Command = join(commands[0])
+		}
+		if layout != nil {
+			imp.unmappedf("window %s: layout %v (mapped onto the main window)", name, layout)
+		}
+		return
+	}
+
+	if layout != nil {
+		imp.unmappedf("window %s: layout %v (pick a vinw-workspace layout instead)", name, layout)
+	}
+	for _, pane := range commands {
+		windowName := name
+		for n := 2; imp.hasWindow(windowName); n++ {
+			windowName = fmt.Sprintf("%s-%d", name, n)
+		}
+		imp.result.Preset.Windows = append(imp.result.Preset.Windows, Window{
+			Name:    windowName,
+			Command: join(pane),
+			Dir:     dir,
+		})
+	}
+	if len(commands) > 1 {
+		imp.unmappedf("window %s: %d panes became separate windows", name, len(commands))
+	}
+}
+
+// layoutWithAgents returns the first built-in layout with exactly n agent
+// panes.
+func layoutWithAgents(n int) (Layout, bool) {
+	for _, layout := range defaultLayouts {
+		root, err := parseWindowgram(layout.Windowgram)
+		if err != nil {
+			continue
+		}
+		count := 0
+		for _, pane := range root.panes() {
+			if strings.ContainsRune(string(agentPanes), pane) {
+				count++
+			}
+		}
+		if count == n {
+			return layout, true
+		}
+	}
+	return Layout{}, false
+}
+
+// selectAgent adds the agent a command starts to the preset. Arguments are
+// not carried over because agents are configured in config.json.
+func (imp *importer) selectAgent(window, command string) bool {
+	words := strings.Fields(command)
+	if len(words) == 0 {
+		return false
+	}
+	for _, agent := range imp.agents {
+		if agent.isNone() || (words[0] != agent.binary() && words[0] != agent.Name) {
+			continue
+		}
+		preset := &imp.result.Preset
+		if !contains(splitAgentNames(preset.Agent), agent.Name) {
+			preset.Agent = strings.Join(append(splitAgentNames(preset.Agent), agent.Name), ",")
+		}
+		if len(words) > 1 {
+			imp.unmappedf("window %s: arguments of %q (set them on the %s agent in config.json)", window, command, agent.Name)
+		}
+		return true
+	}
+	return false
+}
+
+func (imp *importer) hasWindow(name string) bool {
+	return contains(windowNames(imp.result.Preset.Windows), name)
+}
+
+func (imp *importer) unmappedf(format string, args ...any) {
+	imp.result.Unmapped = append(imp.result.Unmapped, fmt.Sprintf(format, args...))
+}
+
+// unknownKeys reports the keys of a definition that the importer does not
+// understand, in a stable order.
+func (imp *importer) unknownKeys(where string, fields map[string]any, known ...string) {
+	var keys []string
+	for key := range fields {
+		if !contains(known, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if where == "" {
+			imp.unmappedf("%s: not supported", key)
+		} else {
+			imp.unmappedf("%s: %s not supported", where, key)
+		}
+	}
+}
+
+// firstValue returns the value of the first key that is set, for formats
+// that renamed keys over time.
+func firstValue(doc map[string]any, keys ...string) any {
+	for _, key := range keys {
+		if v, ok := doc[key]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+func anyList(v any) []any {
+	list, _ := v.([]any)
+	return list
+}
+
+func stringValue(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// stringList reads a command or list of commands. tmuxp also allows a
+// command as {cmd: ...}.
+func stringList(v any) []string {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []any:
+		var out []string
+		for _, item := range v {
+			if m, ok := item.(map[string]any); ok {
+				item = m["cmd"]
+			}
+			if s := stringValue(item); s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		if s := stringValue(v); s != "" {
+			return []string{s}
+		}
+		return nil
+	}
+}
+
+// importProject is the project file equivalent of an imported preset.
+func importProject(preset Preset) ProjectConfig {
+	return ProjectConfig{
+		Session: preset.Session,
+		Agent:   preset.Agent,
+		Windows: preset.Windows,
+	}
+}
+
+// findImportFiles lists the session files in the tmuxinator and tmuxp
+// config directories.
+func findImportFiles() []string {
+	var files []string
+	for _, dir := range importDirs {
+		entries, err := os.ReadDir(expandPath(dir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			switch filepath.Ext(entry.Name()) {
+			case ".yml", ".yaml", ".json":
+				files = append(files, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	return files
+}
+
+// openImport switches to the import screen
+func openImport(m model) (model, tea.Cmd) {
+	m.currentState = stateImport
+	m.imported = nil
+	m.statusMessage = statusMsg{}
+	m.importFiles = findImportFiles()
+	m.importCursor = 0
+	m.importInput.SetValue(firstOr(m.importFiles, ""))
+	m.importInput.CursorEnd()
+	return m, m.importInput.Focus()
+}
+
+// updateImport handles the import screen: pick a file, look at what it
+// converts to, then save it as a preset or project file.
+func updateImport(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	// A converted file waits for a decision
+	if m.imported != nil {
+		preset := m.imported.Preset
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.imported = nil
+			m.statusMessage = statusMsg{}
+			return m, m.importInput.Focus()
+		case "enter", "s":
+			if err := savePreset(preset); err != nil {
+				m.statusMessage = statusMsg{text: err.Error(), isError: true}
+				return m, nil
+			}
+			m.imported = nil
+			m = openPresets(m)
+			for i, p := range m.presets.Presets {
+				if p.Name == preset.Name {
+					m.presetsCursor = i
+				}
+			}
+			m.statusMessage = statusMsg{text: fmt.Sprintf("✓ Imported preset '%s'", preset.Name)}
+			return m, nil
+		case "p":
+			file := filepath.Join(preset.Dir, projectFileName)
+			if _, err := os.Stat(file); err == nil {
+				m.statusMessage = statusMsg{text: file + " already exists", isError: true}
+				return m, nil
+			}
+			if err := saveProjectConfig(preset.Dir, importProject(preset)); err != nil {
+				m.statusMessage = statusMsg{text: err.Error(), isError: true}
+				return m, nil
+			}
+			m.statusMessage = statusMsg{text: "✓ Wrote " + file}
+		}
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.importInput.Blur()
+		return openPresets(m), nil
+
+	case "up", "down":
+		if len(m.importFiles) == 0 {
+			return m, nil
+		}
+		if keyMsg.String() == "up" && m.importCursor > 0 {
+			m.importCursor--
+		}
+		if keyMsg.String() == "down" && m.importCursor < len(m.importFiles)-1 {
+			m.importCursor++
+		}
+		m.importInput.SetValue(m.importFiles[m.importCursor])
+		m.importInput.CursorEnd()
+		return m, nil
+
+	case "enter":
+		result, err := importSessionFile(strings.TrimSpace(m.importInput.Value()), m.directory, m.agentOptions)
+		if err != nil {
+			m.statusMessage = statusMsg{text: err.Error(), isError: true}
+			return m, nil
+		}
+		m.imported = &result
+		m.statusMessage = statusMsg{}
+		m.importInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.importInput, cmd = m.importInput.Update(keyMsg)
+	return m, cmd
+}
+
+// viewImport renders the import screen
+func viewImport(m model) string {
+	var s strings.Builder
+
+	listTitleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(pinkColor).
+		Padding(0, 0, 1, 0)
+
+	s.WriteString(listTitleStyle.Render("📥 Import tmuxinator / tmuxp"))
+	s.WriteString("\n\n")
+
+	if m.imported == nil {
+		s.WriteString(sectionTitleStyle.Render("Session file") + "\n")
+		s.WriteString(m.importInput.View() + "\n\n")
+
+		for i, file := range m.importFiles {
+			line := "  " + blurredStyle.Render(file)
+			if i == m.importCursor {
+				line = successStyle.Render("▸ " + file)
+			}
+			s.WriteString(line + "\n")
+		}
+		if len(m.importFiles) > 0 {
+			s.WriteString("\n")
+		}
+	} else {
+		preset := m.imported.Preset
+		s.WriteString(sectionTitleStyle.Render("From "+m.imported.Format) + "\n")
+		s.WriteString(fmt.Sprintf("  %s %s\n", blurredStyle.Render("Preset:"), successStyle.Render(preset.Name)))
+		s.WriteString(fmt.Sprintf("  %s %s\n", blurredStyle.Render("Directory:"), successStyle.Render(preset.Dir)))
+		s.WriteString(fmt.Sprintf("  %s %s\n", blurredStyle.Render("Session:"), successStyle.Render(preset.Session)))
+		if preset.Agent != "" {
+			s.WriteString(fmt.Sprintf("  %s %s\n", blurredStyle.Render("Agent:"), successStyle.Render(preset.Agent)))
+		}
+		for _, w := range preset.Windows {
+			s.WriteString(fmt.Sprintf("  %s %s %s\n", blurredStyle.Render("Window:"), successStyle.Render(w.Name), blurredStyle.Render("→ "+w.Command)))
+		}
+
+		if len(m.imported.Unmapped) > 0 {
+			s.WriteString("\n" + sectionTitleStyle.Render("Not imported") + "\n")
+			for _, item := range m.imported.Unmapped {
+				s.WriteString(errorStyle.Render("  ⚠ "+item) + "\n")
+			}
+		}
+		s.WriteString("\n")
+	}
+
+	if m.statusMessage.text != "" {
+		statusStyle := successStyle.Bold(true)
+		if m.statusMessage.isError {
+			statusStyle = errorStyle.Bold(true)
+		}
+		s.WriteString(statusStyle.Render(m.statusMessage.text) + "\n\n")
+	}
+
+	helpText := "↑↓: pick a file • enter: import • esc: back"
+	if m.imported != nil {
+		helpText = "enter/s: save as preset • p: write " + projectFileName + " • esc: back"
+	}
+	s.WriteString(helpStyle.Render(helpText))
+
+	// Full-height container
+	fullHeightContainer := lipgloss.NewStyle().
+		Width(m.width-2).
+		Height(m.height-2).
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(purpleColor)
+
+	return fullHeightContainer.Render(s.String())
+}
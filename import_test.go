@@ -0,0 +1,142 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var importAgents = []Agent{{Name: "none"}, {Name: "claude"}, {Name: "codex"}}
+
+func TestImportTmuxinator(t *testing.T) {
+	dir := t.TempDir()
+	data := `
+name: api
+root: ` + dir + `
+pre_window: nvm use
+startup_window: editor
+windows:
+  - editor:
+      layout: main-vertical
+      panes:
+        - claude --resume
+        - codex
+        - npm run dev
+  - logs:
+      layout: even-horizontal
+      panes:
+        - tail -f log/dev.log
+        - worker:
+            - cd worker
+            - bundle exec sidekiq
+  - shell:
+`
+	result, err := importSession([]byte(data), t.TempDir(), importAgents)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Preset{
+		Name:    "api",
+		Session: "api",
+		Dir:     dir,
+		Agent:   "claude,codex",
+		Command: "nvm use; npm run dev",
+		Layout:  "dual-agent",
+		Windows: []Window{
+			{Name: "logs", Command: "nvm use; tail -f log/dev.log"},
+			{Name: "logs-2", Command: "nvm use; cd worker; bundle exec sidekiq"},
+		},
+	}
+	if result.Format != "tmuxinator" {
+		t.Errorf("format = %q, want tmuxinator", result.Format)
+	}
+	if !reflect.DeepEqual(result.Preset, want) {
+		t.Errorf("preset = %+v\nwant %+v", result.Preset, want)
+	}
+	wantUnmapped := []string{
+		"startup_window: not supported",
+		`window editor: arguments of "claude --resume" (set them on the claude agent in config.json)`,
+		"window editor: layout main-vertical (mapped onto the main window)",
+		"window logs: layout even-horizontal (pick a vinw-workspace layout instead)",
+		"window logs: 2 panes became separate windows",
+	}
+	if !reflect.DeepEqual(result.Unmapped, wantUnmapped) {
+		t.Errorf("unmapped = %q\nwant %q", result.Unmapped, wantUnmapped)
+	}
+}
+
+func TestImportTmuxp(t *testing.T) {
+	root := t.TempDir()
+	data := `
+session_name: web app
+start_directory: ./
+shell_command_before:
+  - source .env
+windows:
+  - window_name: dev
+    layout: tiled
+    start_directory: frontend
+    panes:
+      - shell_command:
+          - cmd: npm install
+          - npm start
+      - blank
+      - codex
+      - focus: true
+  - panes:
+      - claude
+      - pane
+`
+	result, err := importSession([]byte(data), root, importAgents)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Preset{
+		Name:    "web app",
+		Session: branchSessionName("web app"),
+		Dir:     root,
+		Agent:   "codex,claude",
+		Layout:  "dual-agent",
+		Windows: []Window{
+			{Name: "dev", Command: "source .env; npm install; npm start", Dir: "frontend"},
+		},
+	}
+	if result.Format != "tmuxp" {
+		t.Errorf("format = %q, want tmuxp", result.Format)
+	}
+	if !reflect.DeepEqual(result.Preset, want) {
+		t.Errorf("preset = %+v\nwant %+v", result.Preset, want)
+	}
+	wantUnmapped := []string{
+		"window dev pane: focus not supported",
+		"window dev: layout tiled (pick a vinw-workspace layout instead)",
+	}
+	if !reflect.DeepEqual(result.Unmapped, wantUnmapped) {
+		t.Errorf("unmapped = %q\nwant %q", result.Unmapped, wantUnmapped)
+	}
+}
+
+func TestImportTmuxpJSON(t *testing.T) {
+	dir := t.TempDir()
+	data := `{"session_name": "docs", "windows": [{"window_name": "serve", "panes": ["mkdocs serve"]}]}`
+	result, err := importSession([]byte(data), dir, importAgents)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Preset.Dir != filepath.Clean(dir) || len(result.Preset.Windows) != 1 || result.Preset.Windows[0].Command != "mkdocs serve" {
+		t.Errorf("preset = %+v", result.Preset)
+	}
+	if len(result.Unmapped) != 1 || !strings.HasPrefix(result.Unmapped[0], "no root directory") {
+		t.Errorf("unmapped = %q, want the missing root", result.Unmapped)
+	}
+}
+
+func TestImportRejectsOtherFiles(t *testing.T) {
+	if _, err := importSession([]byte("foo: bar\n"), t.TempDir(), importAgents); err == nil {
+		t.Error("a file that is neither tmuxinator nor tmuxp was imported")
+	}
+}
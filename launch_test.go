@@ -0,0 +1,558 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// launchFake runs a workspace's launch plan against a fake tmux server.
+func launchFake(t *testing.T, spec workspaceSpec) *fakeTmux {
+	t.Helper()
+	t.Setenv("TMUX", "")
+
+	plan, err := buildLaunchPlan(spec)
+	if err != nil {
+		t.Fatalf("buildLaunchPlan: %v", err)
+	}
+	fake := newFakeTmux()
+	if err := executeLaunchPlan(fake, plan); err != nil {
+		t.Fatalf("executeLaunchPlan: %v", err)
+	}
+	if err := attachClient(fake, plan.Session); err != nil {
+		t.Fatalf("attachClient: %v", err)
+	}
+	return fake
+}
+
+// checkGolden compares got with testdata/launch/<name>.golden.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "launch", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("launch of %s differs from %s:\n--- got\n%s--- want\n%s", name, path, got, want)
+	}
+}
+
+func testSpec(terminal string, agents []Agent, customCmd string) workspaceSpec {
+	return workspaceSpec{
+		Dir:       "/work/api",
+		Session:   "api",
+		Terminal:  terminal,
+		Agents:    agents,
+		SessionID: "0123abcd",
+		CustomCmd: customCmd,
+		Layout:    defaultLayouts[0],
+		PaneStart: paneStartKeys,
+	}
+}
+
+func TestLaunchLayouts(t *testing.T) {
+	agentSets := []struct {
+		name   string
+		agents []Agent
+	}{
+		{"no-agent", nil},
+		{"claude", []Agent{{Name: "claude"}}},
+		{"claude+codex", []Agent{{Name: "claude"}, {Name: "codex", Args: []string{"--model", "o3"}}}},
+	}
+	commands := []struct {
+		name    string
+		command string
+	}{
+		{"", ""},
+		{"-custom", "npm run dev"},
+	}
+
+	for _, terminal := range []string{"shell", "nextui"} {
+		for _, agents := range agentSets {
+			for _, command := range commands {
+				name := terminal + "-" + agents.name + command.name
+				t.Run(name, func(t *testing.T) {
+					fake := launchFake(t, testSpec(terminal, agents.agents, command.command))
+					checkGolden(t, name, fake.String())
+				})
+			}
+		}
+	}
+}
+
+func TestLaunchDualAgentLayout(t *testing.T) {
+	spec := testSpec("shell", []Agent{{Name: "claude"}, {Name: "codex"}, {Name: "crush", Dir: "web"}}, "")
+	spec.Layout = defaultLayouts[len(defaultLayouts)-1]
+	spec.Windows = []Window{{Name: "logs", Command: "tail -f log/dev.log", Dir: "server"}}
+	fake := launchFake(t, spec)
+	checkGolden(t, "dual-agent-windows", fake.String())
+}
+
+func TestLaunchRespawn(t *testing.T) {
+	t.Setenv("SHELL", "/bin/zsh")
+	spec := testSpec("shell", []Agent{{Name: "claude"}}, "make watch")
+	spec.PaneStart = paneStartRespawn
+	spec.Worktree = "/work/api-worktrees/feat"
+	fake := launchFake(t, spec)
+	checkGolden(t, "respawn-worktree", fake.String())
+}
+
+func TestLaunchSwitchesInsideTmux(t *testing.T) {
+	plan, err := buildLaunchPlan(testSpec("shell", nil, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	fake := newFakeTmux()
+	if err := executeLaunchPlan(fake, plan); err != nil {
+		t.Fatal(err)
+	}
+	if err := attachClient(fake, plan.Session); err != nil {
+		t.Fatal(err)
+	}
+	last := fake.Calls[len(fake.Calls)-1]
+	if strings.Join(last, " ") != "switch-client -t api" {
+		t.Errorf("last call = %q, want switch-client -t api", last)
+	}
+}
+
+func TestLaunchRefusesExistingSession(t *testing.T) {
+	t.Setenv("TMUX", "")
+	plan, err := buildLaunchPlan(testSpec("shell", nil, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeTmux()
+	if _, err := fake.NewSession(newSession{Session: "api", Dir: "/elsewhere"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := executeLaunchPlan(fake, plan); err == nil {
+		t.Fatal("launch over an existing session succeeded")
+	}
+	if len(fake.Calls) != 1 {
+		t.Errorf("launch ran %d tmux commands on an existing session", len(fake.Calls)-1)
+	}
+}
+
+func TestSessionQueriesAfterLaunch(t *testing.T) {
+	fake := launchFake(t, testSpec("shell", []Agent{{Name: "claude"}, {Name: "codex"}}, ""))
+	saved := tmuxServer
+	tmuxServer = fake
+	t.Cleanup(func() { tmuxServer = saved })
+
+	sessions := getTmuxSessions()
+	if len(sessions) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(sessions))
+	}
+	got := sessions[0]
+	if got.Name != "api" || got.Path != "/work/api" || !got.Workspace || !got.Attached || got.Windows != 2 || got.Panes != 5 {
+		t.Errorf("session summary = %+v", got)
+	}
+
+	// Pane c is the fourth pane created; codex has a window of its own
+	for role, want := range map[string]string{"vinw": "%0", "vinw-viewer": "%1", "terminal": "%2", "agent": "%3"} {
+		id, err := findRolePane("api", role)
+		if err != nil || id != want {
+			t.Errorf("findRolePane(%s) = %q, %v; want %s", role, id, err, want)
+		}
+	}
+
+	info, err := describeSession("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(info.PaneCommands, " ") != "vinw vinw-viewer bash claude codex" {
+		t.Errorf("pane commands = %v", info.PaneCommands)
+	}
+}
+
+func TestSessionQueriesWithSeparatorInPaths(t *testing.T) {
+	spec := testSpec("shell", nil, "")
+	spec.Dir = "/work/a" + fieldSep + "b"
+	spec.Worktree = "/work/trees" + fieldSep + "feat"
+	fake := launchFake(t, spec)
+	saved := tmuxServer
+	tmuxServer = fake
+	t.Cleanup(func() { tmuxServer = saved })
+
+	sessions := getTmuxSessions()
+	if len(sessions) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(sessions))
+	}
+	got := sessions[0]
+	if got.Name != "api" || got.Path != spec.Dir || got.Worktree != spec.Worktree || !got.Workspace || got.Windows != 1 || got.Panes != 4 {
+		t.Errorf("session summary = %+v", got)
+	}
+}
+
+func TestLaunchFailureRemovesSession(t *testing.T) {
+	t.Setenv("TMUX", "")
+	plan, err := buildLaunchPlan(testSpec("shell", []Agent{{Name: "claude"}}, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		command  string
+		step     string
+		rollback bool
+	}{
+		{"new-session", "create session 'api'", false},
+		{"split-window", "split pane a for pane v", true},
+		{"send-keys", "start vinw", true},
+		{"select-pane", "focus pane v", true},
+	} {
+		t.Run(tt.command, func(t *testing.T) {
+			fake := newFakeTmux()
+			fake.Fail = map[string]string{tt.command: "no space for new pane"}
+			err := executeLaunchPlan(fake, plan)
+			if err == nil {
+				t.Fatal("launch succeeded")
+			}
+			if !strings.Contains(err.Error(), "failed to "+tt.step+": tmux "+tt.command+": no space for new pane") {
+				t.Errorf("error %q does not name the step %q and the tmux message", err, tt.step)
+			}
+			if len(fake.Sessions) != 0 {
+				t.Errorf("half-built session left behind:\n%s", fake)
+			}
+			killed := strings.Join(fake.Calls[len(fake.Calls)-1], " ") == "kill-session -t api:"
+			if killed != tt.rollback {
+				t.Errorf("kill-session run = %v, want %v", killed, tt.rollback)
+			}
+		})
+	}
+}
+
+func TestLaunchWaitsForReadyPanes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".ready"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := testSpec("shell", []Agent{{Name: "claude"}}, "npm run dev")
+	spec.Dir = dir
+	spec.Layout = defaultLayouts[2] // agent-on-top: a, c, v, t
+	spec.Layout.Ready = map[string]ReadyCheck{
+		"a": {File: ".ready"},
+		"t": {Output: `npm run [a-z]+`, Delay: "10ms"},
+	}
+	spec.Windows = []Window{{Name: "logs", Command: "tail -f dev.log", Ready: &ReadyCheck{Output: "tail"}}}
+	plan, err := buildLaunchPlan(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Panes and windows with a ready check start first, so the agent
+	// above the terminal pane still waits for it
+	var order []string
+	for _, step := range plan.Steps {
+		if step.Wait != nil || strings.HasPrefix(step.Desc, "start") {
+			order = append(order, step.Desc)
+		}
+	}
+	want := []string{
+		"start vinw", "start vinw", "wait for pane a (file " + filepath.Join(dir, ".ready") + ")",
+		"start terminal command", "start terminal command", `wait for pane t (output /npm run [a-z]+/, 10ms delay)`,
+		"start window logs", "start window logs", "wait for window logs (output /tail/)",
+		"start agent claude", "start agent claude",
+		"start vinw-viewer", "start vinw-viewer",
+	}
+	if strings.Join(order, "\n") != strings.Join(want, "\n") {
+		t.Errorf("steps in order:\n%s\nwant:\n%s", strings.Join(order, "\n"), strings.Join(want, "\n"))
+	}
+
+	t.Setenv("TMUX", "")
+	fake := newFakeTmux()
+	if err := executeLaunchPlan(fake, plan); err != nil {
+		t.Fatalf("executeLaunchPlan: %v", err)
+	}
+	if !strings.Contains(fake.String(), "$ vinw-viewer") {
+		t.Errorf("launch did not reach the last pane:\n%s", fake)
+	}
+}
+
+func TestLaunchWaitTimesOut(t *testing.T) {
+	t.Setenv("TMUX", "")
+	spec := testSpec("shell", nil, "")
+	spec.Layout.Ready = map[string]ReadyCheck{"v": {File: "never", Timeout: "300ms"}}
+	plan, err := buildLaunchPlan(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := newFakeTmux()
+	err = executeLaunchPlan(fake, plan)
+	if err == nil || !strings.Contains(err.Error(), "failed to wait for pane v (file /work/api/never): timed out after 300ms") {
+		t.Fatalf("error = %v, want a timeout for pane v", err)
+	}
+	if len(fake.Sessions) != 0 {
+		t.Errorf("session left behind after a timeout:\n%s", fake)
+	}
+}
+
+func TestInvalidReadyChecks(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		ready map[string]ReadyCheck
+		want  string
+	}{
+		{"unknown pane", map[string]ReadyCheck{"z": {Delay: "1s"}}, `for pane "z", which it does not have`},
+		{"several letters", map[string]ReadyCheck{"at": {Delay: "1s"}}, `for pane "at"`},
+		{"empty", map[string]ReadyCheck{"t": {}}, "nothing to wait for"},
+		{"bad pattern", map[string]ReadyCheck{"t": {Output: "("}}, "invalid output pattern"},
+		{"perl class", map[string]ReadyCheck{"t": {Output: `listening on \d+`}}, `\d is not POSIX extended syntax`},
+		{"flags", map[string]ReadyCheck{"t": {Output: "(?i)ready"}}, "(? is not POSIX extended syntax"},
+		{"lazy", map[string]ReadyCheck{"t": {Output: "a.*?b"}}, "*? is not POSIX extended syntax"},
+		{"escape in brackets", map[string]ReadyCheck{"t": {Output: `[\d.]+`}}, `\d is not POSIX extended syntax`},
+		{"bad duration", map[string]ReadyCheck{"t": {Port: 3000, Timeout: "soon"}}, `invalid duration "soon"`},
+		{"bad port", map[string]ReadyCheck{"t": {Port: 70000}}, "invalid port 70000"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := testSpec("shell", nil, "")
+			spec.Layout.Ready = tt.ready
+			_, err := buildLaunchPlan(spec)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// runLaunching feeds the results of cmd back into the model until the
+// launch needs a key or the TUI quits. Spinner ticks are dropped.
+func runLaunching(m model, cmd tea.Cmd) (model, bool) {
+	pending := []tea.Cmd{cmd}
+	for len(pending) > 0 {
+		cmd, pending = pending[0], pending[1:]
+		if cmd == nil {
+			continue
+		}
+		switch msg := cmd().(type) {
+		case tea.QuitMsg:
+			return m, true
+		case tea.BatchMsg:
+			pending = append(pending, msg...)
+		case spinner.TickMsg:
+		default:
+			next, cmd := m.Update(msg)
+			m = next.(model)
+			pending = append(pending, cmd)
+		}
+	}
+	return m, false
+}
+
+func pressKey(m model, key string) (model, bool) {
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	if key == "esc" {
+		msg = tea.KeyMsg{Type: tea.KeyEsc}
+	}
+	next, cmd := m.Update(msg)
+	return runLaunching(next.(model), cmd)
+}
+
+func TestLaunchingScreen(t *testing.T) {
+	start := func(t *testing.T, fail map[string]string) (model, *fakeTmux, bool) {
+		t.Setenv("HOME", t.TempDir())
+		fake := newFakeTmux()
+		fake.Fail = fail
+		saved := tmuxServer
+		tmuxServer = fake
+		t.Cleanup(func() { tmuxServer = saved })
+
+		m := initialModel()
+		m.currentState = statePreview
+		next, cmd := m.beginLaunch(testSpec("shell", []Agent{{Name: "claude"}}, ""), nil, conflictFail)
+		m, quit := runLaunching(next.(model), cmd)
+		return m, fake, quit
+	}
+
+	t.Run("retry", func(t *testing.T) {
+		t.Setenv("TMUX", "")
+		m, fake, quit := start(t, map[string]string{"send-keys": "pane is dead"})
+		if quit || m.launchRun.err == nil || !strings.Contains(m.launchRun.err.Error(), "failed to start vinw: tmux send-keys: pane is dead") {
+			t.Fatalf("quit = %v, err = %v; want the launch stopped at start vinw", quit, m.launchRun.err)
+		}
+		if !strings.Contains(m.View(), "s: skip") {
+			t.Errorf("a failed send-keys cannot be skipped:\n%s", m.View())
+		}
+
+		fake.Fail = nil
+		m, quit = pressKey(m, "r")
+		if !quit || m.attachTarget != "api" {
+			t.Fatalf("quit = %v, attach = %q after retry; want to attach to api", quit, m.attachTarget)
+		}
+		if !strings.Contains(fake.String(), "$ vinw\n") {
+			t.Errorf("vinw was not started after the retry:\n%s", fake)
+		}
+		if presets, _ := loadPresets(); len(presets.Recent) != 1 {
+			t.Errorf("%d recent launches after the retry, want 1", len(presets.Recent))
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		t.Setenv("TMUX", "")
+		m, _, _ := start(t, map[string]string{"select-pane": "no such pane"})
+		m, quit := pressKey(m, "s")
+		if !quit || m.attachTarget != "api" {
+			t.Fatalf("quit = %v, attach = %q after skipping the focus; want to attach to api", quit, m.attachTarget)
+		}
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		t.Setenv("TMUX", "")
+		m, fake, _ := start(t, map[string]string{"split-window": "no space for new pane"})
+		if strings.Contains(m.View(), "s: skip") {
+			t.Errorf("a failed split can be skipped:\n%s", m.View())
+		}
+		m, quit := pressKey(m, "s")
+		if quit || m.launchRun.err == nil {
+			t.Fatal("skipping a split went ahead")
+		}
+
+		m, _ = pressKey(m, "esc")
+		if m.currentState != statePreview || len(fake.Sessions) != 0 {
+			t.Errorf("state = %v, sessions:\n%s; want the preview and no session", m.currentState, fake)
+		}
+		if !strings.Contains(m.statusMessage.text, "removed the partly created session 'api'") {
+			t.Errorf("status = %q", m.statusMessage.text)
+		}
+		presets, _ := loadPresets()
+		history, _ := loadHistory()
+		if len(presets.Recent) != 0 || len(history.Directories) != 0 {
+			t.Errorf("aborted launch recorded: %d recent launches, %d directories", len(presets.Recent), len(history.Directories))
+		}
+	})
+
+	t.Run("failed session", func(t *testing.T) {
+		t.Setenv("TMUX", "")
+		m, _, _ := start(t, map[string]string{"new-session": "server exited"})
+		if view := m.View(); !strings.Contains(view, "esc: back") || strings.Contains(view, "remove the session") {
+			t.Errorf("help offers to remove a session that was never created:\n%s", view)
+		}
+	})
+
+	t.Run("cancel while creating", func(t *testing.T) {
+		t.Setenv("TMUX", "")
+		t.Setenv("HOME", t.TempDir())
+		fake := newFakeTmux()
+		fake.Fail = map[string]string{"kill-session": "server exited"}
+		saved := tmuxServer
+		tmuxServer = fake
+		t.Cleanup(func() { tmuxServer = saved })
+
+		m := initialModel()
+		m.currentState = statePreview
+		next, cmd := m.beginLaunch(testSpec("shell", nil, ""), nil, conflictFail)
+		m, _ = pressKey(next.(model), "esc")
+		// The session is created after the launch was cancelled
+		m, _ = runLaunching(m, cmd)
+		if !strings.Contains(m.statusMessage.text, "failed to kill session 'api'") {
+			t.Errorf("status = %q, want the failed kill", m.statusMessage.text)
+		}
+	})
+
+	t.Run("quit while creating", func(t *testing.T) {
+		fake := cliEnv(t)
+		m := initialModel()
+		m.currentState = statePreview
+		next, cmd := m.beginLaunch(testSpec("shell", nil, ""), nil, conflictFail)
+		m, quit := pressKey(next.(model), "q")
+		if quit {
+			t.Fatal("quit before the session being created was removed")
+		}
+		// new-session reports after q was pressed
+		if _, quit = runLaunching(m, cmd); !quit || len(fake.Sessions) != 0 {
+			t.Errorf("quit = %v, sessions:\n%s; want to quit with no session left", quit, fake)
+		}
+	})
+
+	t.Run("relaunch while creating", func(t *testing.T) {
+		fake := cliEnv(t)
+		m := initialModel()
+		m.currentState = statePreview
+		spec := testSpec("shell", nil, "")
+		spec.Session = "old"
+		next, oldCmd := m.beginLaunch(spec, nil, conflictFail)
+		m, _ = pressKey(next.(model), "esc")
+		next, cmd := m.beginLaunch(testSpec("shell", nil, ""), nil, conflictFail)
+		m, _ = runLaunching(next.(model), cmd)
+
+		// The first launch's new-session reports only now
+		runLaunching(m, oldCmd)
+		if len(fake.Sessions) != 1 || fake.Sessions[0].Name != "api" {
+			t.Errorf("sessions:\n%s; want only api", fake)
+		}
+	})
+
+	t.Run("switch inside tmux", func(t *testing.T) {
+		t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+		m, fake, quit := start(t, nil)
+		if !quit || m.attachTarget != "" || fake.Attached != "api" {
+			t.Errorf("quit = %v, attach = %q, switched to %q; want a switch from the TUI", quit, m.attachTarget, fake.Attached)
+		}
+	})
+}
+
+func TestLaunchingRowsKeepWorktreeStep(t *testing.T) {
+	spec := testSpec("shell", nil, "")
+	spec.Worktree = "/work/api-worktrees/feat"
+	plan, err := buildLaunchPlan(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var descs []string
+	for _, row := range (launchProgress{plan: plan}).rows() {
+		descs = append(descs, row.desc)
+	}
+	got := strings.Join(descs, "\n")
+	if !strings.Contains(got, "mark session\nrecord worktree\n") {
+		t.Errorf("checklist rows:\n%s\nwant mark session followed by record worktree", got)
+	}
+}
+
+func TestPreviewLooksUpSessionOnce(t *testing.T) {
+	fake := cliEnv(t)
+	installFakes(t, "vinw", "vinw-viewer", "tmux", "claude")
+	if _, err := fake.NewSession(newSession{Session: "api", Dir: "/elsewhere"}); err != nil {
+		t.Fatal(err)
+	}
+	m := initialModel()
+	m.currentState = stateForm
+	m.inputs[0].SetValue("api")
+	m.focusIndex = focusLaunch
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = runLaunching(next.(model), cmd)
+	if m.currentState != statePreview {
+		t.Fatalf("state = %v, want the preview", m.currentState)
+	}
+
+	calls := len(fake.Calls)
+	view := m.View()
+	m.View()
+	if len(fake.Calls) != calls {
+		t.Errorf("rendering the preview ran tmux: %q", fake.Calls[calls:])
+	}
+	for _, want := range []string{"Session 'api' already exists", "/elsewhere", "n: new as api-2"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("preview does not show %q:\n%s", want, view)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// launchProgress is a launch plan being run from the TUI, one step per
+// message so the launching screen can show where it is. A failed step
+// waits for the user to retry it, skip it or abort the launch.
+type launchProgress struct {
+	run       int // tells messages of a cancelled launch from the current one
+	plan      launchPlan
+	worktree  *worktreePlan // created before the plan's steps, nil when it exists
+	recent    Preset        // recorded as a recent launch once every step is done
+	ids       map[string]string
+	next      int       // step running now; -1 is the worktree, len(plan.Steps) the handoff
+	waitStart time.Time // when the running wait began
+	waited    time.Duration
+	err       error // why the current step failed, nil while it runs
+	cancelled bool
+	quit      bool // quit once the worktree or session being created reports
+}
+
+// launchWorktreeMsg reports the git worktree add before the first step.
+type launchWorktreeMsg struct {
+	run int
+	err error
+}
+
+// launchStepMsg reports a finished tmux step.
+type launchStepMsg struct {
+	run     int
+	session string // session the step created, "" for every other step
+	out     string
+	err     error
+}
+
+// launchProbeMsg reports one try of a readiness check.
+type launchProbeMsg struct {
+	run   int
+	ready bool
+	err   error
+}
+
+// launchHandoffMsg reports the switch to the new session inside tmux.
+type launchHandoffMsg struct {
+	run int
+	err error
+}
+
+// beginLaunch settles a session name conflict and starts running the
+// launch plan on the launching screen, creating the worktree first when
+// one is planned.
+func (m model) beginLaunch(spec workspaceSpec, worktree *worktreePlan, onConflict string) (tea.Model, tea.Cmd) {
+	session, attach, err := resolveSessionConflict(spec.Session, onConflict)
+	if err != nil {
+		m.statusMessage = statusMsg{text: err.Error(), isError: true}
+		return m, nil
+	}
+	if attach {
+		m.attachTarget = session
+		return m, tea.Quit
+	}
+
+	spec.Session = session
+	plan, err := buildLaunchPlan(spec)
+	if err != nil {
+		m.statusMessage = statusMsg{text: err.Error(), isError: true}
+		return m, nil
+	}
+
+	recent := m.formPreset("")
+	recent.Session = session
+
+	m.currentState = stateLaunching
+	m.statusMessage = statusMsg{}
+	m.launchRun = launchProgress{run: m.launchRun.run + 1, plan: plan, recent: recent, ids: map[string]string{}}
+	if worktree != nil && worktree.Add != nil {
+		m.launchRun.worktree = worktree
+		m.launchRun.next = -1
+	}
+	cmd := m.launchRun.start()
+	return m, tea.Batch(cmd, m.spinner.Tick)
+}
+
+// stale reports whether a step result belongs to a launch that was aborted
+// or replaced since the step started.
+func (m model) stale(run int) bool {
+	return run != m.launchRun.run || !m.launchRunning()
+}
+
+// launchRunning reports whether a launch step is in progress.
+func (m model) launchRunning() bool {
+	return m.currentState == stateLaunching && m.launchRun.err == nil && !m.launchRun.cancelled
+}
+
+// start runs the current step: the worktree or a tmux step right away, a
+// readiness check after the poll interval, and after the last step the
+// switch to the new session.
+func (p *launchProgress) start() tea.Cmd {
+	run := p.run
+	if p.next < 0 {
+		worktree := *p.worktree
+		return func() tea.Msg {
+			return launchWorktreeMsg{run: run, err: worktree.create()}
+		}
+	}
+	if p.next == len(p.plan.Steps) {
+		session := p.plan.Session
+		return func() tea.Msg {
+			return launchHandoffMsg{run: run, err: tmuxServer.SwitchClient(session)}
+		}
+	}
+
+	step, ids := p.plan.Steps[p.next], p.ids
+	if step.Wait == nil {
+		created := ""
+		if p.next == 0 {
+			created = p.plan.Session
+		}
+		return func() tea.Msg {
+			out, err := step.Cmd.run(tmuxServer, ids)
+			return launchStepMsg{run: run, session: created, out: out, err: err}
+		}
+	}
+	pane := step.waitPane(ids)
+
+	if p.waitStart.IsZero() {
+		p.waitStart = time.Now()
+	}
+	check, started := *step.Wait, p.waitStart
+	return tea.Tick(readyPollInterval, func(time.Time) tea.Msg {
+		ready, err := check.probe(tmuxServer, pane, time.Since(started))
+		return launchProbeMsg{run: run, ready: ready, err: err}
+	})
+}
+
+// advance moves on to the next step. Once every step is done the launch
+// is recorded; inside tmux the client switches to the session from here,
+// outside the TUI quits so main can attach this terminal.
+func (m model) advance() (tea.Model, tea.Cmd) {
+	m.launchRun.next++
+	m.launchRun.waitStart, m.launchRun.waited = time.Time{}, 0
+	if m.launchRun.next == len(m.launchRun.plan.Steps) {
+		recordRecent(m.launchRun.recent)
+		recordDirectory(m.launchRun.recent.Dir)
+		if !isInTmux() {
+			m.attachTarget = m.launchRun.plan.Session
+			return m, tea.Quit
+		}
+	}
+	cmd := m.launchRun.start()
+	return m, cmd
+}
+
+// fail stops the launch at the current step until the user decides what
+// to do with it.
+func (m model) fail(err error) (tea.Model, tea.Cmd) {
+	m.launchRun.err = fmt.Errorf("failed to %s: %w", m.launchRun.desc(m.launchRun.next), err)
+	return m, nil
+}
+
+// desc describes a step, including the worktree and the handoff.
+func (p launchProgress) desc(step int) string {
+	if step < 0 {
+		return fmt.Sprintf("create worktree for %s", p.worktree.Branch)
+	}
+	if step == len(p.plan.Steps) && isInTmux() {
+		return fmt.Sprintf("switch to '%s'", p.plan.Session)
+	}
+	if step == len(p.plan.Steps) {
+		return fmt.Sprintf("attach to '%s'", p.plan.Session)
+	}
+	return p.plan.Steps[step].Desc
+}
+
+// canSkip reports whether the failed step can be left out. Every pane
+// starts in the worktree, later steps need the pane a create or split
+// step prints, and the handoff is the point of the launch.
+func (p launchProgress) canSkip() bool {
+	return p.next >= 0 && p.next < len(p.plan.Steps) && p.plan.Steps[p.next].Output == ""
+}
+
+// updateLaunchProgress handles the result of a step
+func (m model) updateLaunchProgress(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case launchWorktreeMsg:
+		// An aborted launch keeps a worktree that was checked out anyway,
+		// like killing a session does
+		if msg.run == m.launchRun.run && m.launchRun.quit {
+			return m, tea.Quit
+		}
+		if m.stale(msg.run) {
+			return m, nil
+		}
+		if msg.err != nil {
+			return m.fail(msg.err)
+		}
+		return m.advance()
+
+	case launchStepMsg:
+		// A session created just as its launch was aborted is removed too,
+		// even once another launch has started
+		cancelled := msg.run != m.launchRun.run || m.launchRun.cancelled
+		if msg.session != "" && msg.err == nil && cancelled {
+			if err := killSession(msg.session); err != nil {
+				m.statusMessage = statusMsg{text: err.Error(), isError: true}
+			}
+		}
+		if msg.run == m.launchRun.run && m.launchRun.quit {
+			return m, tea.Quit
+		}
+		if m.stale(msg.run) {
+			return m, nil
+		}
+		if msg.err != nil {
+			return m.fail(msg.err)
+		}
+		if output := m.launchRun.plan.Steps[m.launchRun.next].Output; output != "" {
+			m.launchRun.ids[output] = msg.out
+		}
+		return m.advance()
+
+	case launchProbeMsg:
+		if m.stale(msg.run) {
+			return m, nil
+		}
+		check := m.launchRun.plan.Steps[m.launchRun.next].Wait
+		m.launchRun.waited = time.Since(m.launchRun.waitStart)
+		switch {
+		case msg.err != nil:
+			return m.fail(msg.err)
+		case msg.ready:
+			return m.advance()
+		case m.launchRun.waited > check.timeout():
+			return m.fail(fmt.Errorf("timed out after %s", check.timeout()))
+		}
+		cmd := m.launchRun.start()
+		return m, cmd
+
+	case launchHandoffMsg:
+		if m.stale(msg.run) {
+			return m, nil
+		}
+		if msg.err != nil {
+			return m.fail(msg.err)
+		}
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// updateLaunching handles keys on the launching screen
+func updateLaunching(msg tea.KeyMsg, m model) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		// The session being created is only known once tmux reports, so
+		// quitting waits for that to remove it, and for git to finish a
+		// worktree
+		creating := m.launchRunning() && m.launchRun.next <= 0
+		m.abortLaunch()
+		if creating {
+			m.launchRun.quit = true
+			return m, nil
+		}
+		return m, tea.Quit
+
+	case "esc":
+		m.abortLaunch()
+		m.currentState = statePreview
+		return m, nil
+
+	case "r":
+		if m.launchRun.err == nil {
+			return m, nil
+		}
+		m.launchRun.err = nil
+		m.launchRun.waitStart, m.launchRun.waited = time.Time{}, 0
+		cmd := m.launchRun.start()
+		return m, tea.Batch(cmd, m.spinner.Tick)
+
+	case "s":
+		if m.launchRun.err == nil || !m.launchRun.canSkip() {
+			return m, nil
+		}
+		m.launchRun.err = nil
+		next, cmd := m.advance()
+		return next, tea.Batch(cmd, m.spinner.Tick)
+	}
+	return m, nil
+}
+
+// abortLaunch stops a launch. A session that is not finished yet is
+// removed so it does not block the next launch; one that only failed to
+// switch over is kept.
+func (m *model) abortLaunch() {
+	p := &m.launchRun
+	if p.cancelled {
+		return
+	}
+	session := p.plan.Session
+	p.cancelled = true
+
+	switch {
+	case p.next == len(p.plan.Steps):
+		m.statusMessage = statusMsg{text: fmt.Sprintf("Session '%s' is running - press a to attach", session), isError: true}
+	case p.next > 0:
+		if err := killSession(session); err != nil {
+			m.statusMessage = statusMsg{text: err.Error(), isError: true}
+			return
+		}
+		m.statusMessage = statusMsg{text: fmt.Sprintf("Launch stopped at '%s'; removed the partly created session '%s'", p.desc(p.next), session), isError: true}
+	case p.err != nil:
+		m.statusMessage = statusMsg{text: p.err.Error(), isError: true}
+	default:
+		m.statusMessage = statusMsg{text: fmt.Sprintf("Launch of '%s' cancelled", session), isError: true}
+	}
+}
+
+// launchRow is one line of the launching checklist: consecutive steps with
+// the same description, like the two send-keys that start a pane, or the
+// handoff at the end.
+type launchRow struct {
+	desc        string
+	first, last int // step indexes
+}
+
+func (p launchProgress) rows() []launchRow {
+	var rows []launchRow
+	if p.worktree != nil {
+		rows = append(rows, launchRow{desc: p.desc(-1), first: -1, last: -1})
+	}
+	for i, step := range p.plan.Steps {
+		if n := len(rows); n > 0 && rows[n-1].desc == step.Desc {
+			rows[n-1].last = i
+			continue
+		}
+		rows = append(rows, launchRow{desc: step.Desc, first: i, last: i})
+	}
+	handoff := len(p.plan.Steps)
+	return append(rows, launchRow{desc: p.desc(handoff), first: handoff, last: handoff})
+}
+
+// viewLaunching renders the launch checklist
+func viewLaunching(m model) string {
+	var s strings.Builder
+	p := m.launchRun
+
+	s.WriteString(sectionTitleStyle.Render("Launching") + "  " + blurredStyle.Render(p.plan.Session) + "\n\n")
+
+	rows := p.rows()
+	current := 0
+	for i, row := range rows {
+		if p.next >= row.first && p.next <= row.last {
+			current = i
+		}
+	}
+
+	// Keep the running step in view on short terminals
+	visible := m.height - 12
+	if visible < 3 {
+		visible = 3
+	}
+	first := 0
+	if len(rows) > visible {
+		first = current - visible/2
+		if first < 0 {
+			first = 0
+		}
+		if first > len(rows)-visible {
+			first = len(rows) - visible
+		}
+	}
+
+	for i := first; i < len(rows) && i < first+visible; i++ {
+		row := rows[i]
+		switch {
+		case p.next > row.last:
+			s.WriteString(successStyle.Render("  ✓ ") + row.desc + "\n")
+		case i == current && p.err != nil:
+			s.WriteString(errorStyle.Render("  ✗ "+row.desc) + "\n")
+		case i == current:
+			line := "  " + m.spinner.View() + " " + focusedStyle.Render(row.desc)
+			if p.next >= 0 && p.next < len(p.plan.Steps) && p.plan.Steps[p.next].Wait != nil {
+				check := p.plan.Steps[p.next].Wait
+				line += blurredStyle.Render(fmt.Sprintf("  %.1fs / %s", p.waited.Seconds(), check.timeout()))
+			}
+			s.WriteString(line + "\n")
+		default:
+			s.WriteString(blurredStyle.Render("  · "+row.desc) + "\n")
+		}
+	}
+	s.WriteString("\n")
+
+	switch {
+	case p.err != nil && p.next == len(p.plan.Steps):
+		s.WriteString(errorStyle.Width(m.width-8).Render(p.err.Error()) + "\n\n")
+		s.WriteString(helpStyle.Render("r: retry • esc: back, keeping the session • q: quit"))
+	case p.err != nil:
+		s.WriteString(errorStyle.Width(m.width-8).Render(p.err.Error()) + "\n\n")
+		help := "r: retry • "
+		if p.canSkip() {
+			help += "s: skip • "
+		}
+		// Nothing is left to remove when the session itself failed
+		if p.next <= 0 {
+			help += "esc: back • q: quit"
+		} else {
+			help += "esc: abort and remove the session • q: abort and quit"
+		}
+		s.WriteString(helpStyle.Render(help))
+	case p.quit && p.next < 0:
+		s.WriteString(helpStyle.Render("quitting once git has created the worktree…"))
+	case p.quit:
+		s.WriteString(helpStyle.Render("quitting once the session being created can be removed…"))
+	default:
+		s.WriteString(helpStyle.Render("esc: cancel • q: cancel and quit"))
+	}
+
+	container := lipgloss.NewStyle().
+		Width(m.width-2).
+		Height(m.height-2).
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(purpleColor)
+
+	return container.Render(s.String())
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Layout is a named windowgram describing how a workspace window is split.
+// Each letter in the windowgram is one pane and must form a rectangle.
+type Layout struct {
+	Name       string                `json:"name"`
+	Windowgram string                `json:"windowgram"`
+	Ready      map[string]ReadyCheck `json:"ready,omitempty"` // pane letter -> what to wait for after it starts
+}
+
+// Pane letters with a built-in role. When several agents are picked, d, e,
+// ... hold the extra ones; any other letter becomes an empty shell.
+const (
+	paneVinw     = 'a'
+	paneViewer   = 'v'
+	paneTerminal = 't'
+	paneAgent    = 'c' // first agent; more agents use d, e, ... (see agentPanes)
+)
+
+// vinwWidth is the width of vinw's pane in columns whenever it has panes
+// beside it, whatever share of the windowgram it covers.
+const vinwWidth = 43
+
+var defaultLayouts = []Layout{
+	{
+		Name: "default",
+		Windowgram: `aaaaaavvvvvvvvvvvvvvvvvvvvvvv
+aaaaaavvvvvvvvvvvvvvvvvvvvvvv
+aaaaaavvvvvvvvvvvvvvvvvvvvvvv
+aaaaaattttttttttttccccccccccc
+aaaaaattttttttttttccccccccccc
+aaaaaattttttttttttccccccccccc`,
+	},
+	{
+		Name: "three-column",
+		Windowgram: `aaaaaavvvvvvvvvvvcccccccccccc
+aaaaaavvvvvvvvvvvcccccccccccc
+aaaaaavvvvvvvvvvvcccccccccccc
+aaaaaavvvvvvvvvvvcccccccccccc
+aaaaaatttttttttttcccccccccccc
+aaaaaatttttttttttcccccccccccc`,
+	},
+	{
+		Name: "agent-on-top",
+		Windowgram: `aaaaaacccccccccccccccccccccccc
+aaaaaacccccccccccccccccccccccc
+aaaaaacccccccccccccccccccccccc
+aaaaaavvvvvvvvvvvvvvvttttttttt
+aaaaaavvvvvvvvvvvvvvvttttttttt
+aaaaaavvvvvvvvvvvvvvvttttttttt`,
+	},
+	{
+		Name: "dual-agent",
+		Windowgram: `aaaaaaccccccccccccddddddddddd
+aaaaaaccccccccccccddddddddddd
+aaaaaaccccccccccccddddddddddd
+aaaaaaccccccccccccddddddddddd
+aaaaaavvvvvvvvvvvvttttttttttt
+aaaaaavvvvvvvvvvvvttttttttttt`,
+	},
+}
+
+// layoutNode is one cell of a parsed windowgram. Leaves hold a pane letter,
+// inner nodes hold children that split the node along a single axis.
+type layoutNode struct {
+	Pane     rune
+	Vertical bool // children are stacked top to bottom
+	Children []*layoutNode
+	Size     int // extent along the parent's split axis, in windowgram cells
+}
+
+// layoutSplit is one tmux split-window call needed to build a layout.
+type layoutSplit struct {
+	Target     rune // existing pane that is split
+	New        rune // pane created by the split
+	Horizontal bool // new pane goes to the right (-h) instead of below (-v)
+	Percent    int  // size of the new pane relative to the target
+}
+
+// paneRect is the bounding box of one pane letter in the windowgram grid.
+type paneRect struct {
+	top, left, bottom, right int // bottom and right are exclusive
+}
+
+// parseWindowgram turns a letter grid into a split tree.
+func parseWindowgram(windowgram string) (*layoutNode, error) {
+	grid := windowgramGrid(windowgram)
+	if len(grid) == 0 {
+		return nil, fmt.Errorf("windowgram is empty")
+	}
+
+	width := len(grid[0])
+	rects := map[rune]*paneRect{}
+	counts := map[rune]int{}
+	for r, row := range grid {
+		if len(row) != width {
+			return nil, fmt.Errorf("windowgram line %d is %d wide, expected %d", r+1, len(row), width)
+		}
+		for c, ch := range row {
+			if !isPaneLetter(ch) {
+				return nil, fmt.Errorf("invalid pane character %q at line %d", ch, r+1)
+			}
+			counts[ch]++
+			rect, ok := rects[ch]
+			if !ok {
+				rects[ch] = &paneRect{top: r, left: c, bottom: r + 1, right: c + 1}
+				continue
+			}
+			rect.top = min(rect.top, r)
+			rect.left = min(rect.left, c)
+			rect.bottom = max(rect.bottom, r+1)
+			rect.right = max(rect.right, c+1)
+		}
+	}
+
+	// Every pane must fill its bounding box exactly
+	for ch, rect := range rects {
+		if (rect.bottom-rect.top)*(rect.right-rect.left) != counts[ch] {
+			return nil, fmt.Errorf("pane %q is not a rectangle", ch)
+		}
+	}
+
+	all := paneRect{top: 0, left: 0, bottom: len(grid), right: width}
+	return buildLayoutNode(rects, all)
+}
+
+// windowgramGrid splits a windowgram into rows, ignoring blank lines and
+// surrounding whitespace.
+func windowgramGrid(windowgram string) [][]rune {
+	var grid [][]rune
+	for _, line := range strings.Split(windowgram, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			grid = append(grid, []rune(line))
+		}
+	}
+	return grid
+}
+
+func isPaneLetter(ch rune) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+// buildLayoutNode recursively cuts the area into columns, then rows.
+func buildLayoutNode(rects map[rune]*paneRect, area paneRect) (*layoutNode, error) {
+	var panes []rune
+	for ch, rect := range rects {
+		if rect.top >= area.top && rect.bottom <= area.bottom &&
+			rect.left >= area.left && rect.right <= area.right {
+			panes = append(panes, ch)
+		}
+	}
+	if len(panes) == 1 {
+		return &layoutNode{Pane: panes[0]}, nil
+	}
+
+	for _, vertical := range []bool{false, true} {
+		lo, hi := area.left, area.right
+		if vertical {
+			lo, hi = area.top, area.bottom
+		}
+
+		// A cut is valid when no pane straddles it
+		cuts := []int{lo}
+		for pos := lo + 1; pos < hi; pos++ {
+			clean := true
+			for _, ch := range panes {
+				start, end := rects[ch].left, rects[ch].right
+				if vertical {
+					start, end = rects[ch].top, rects[ch].bottom
+				}
+				if start < pos && end > pos {
+					clean = false
+					break
+				}
+			}
+			if clean {
+				cuts = append(cuts, pos)
+			}
+		}
+		if len(cuts) == 1 {
+			continue
+		}
+		cuts = append(cuts, hi)
+
+		node := &layoutNode{Vertical: vertical}
+		for i := 0; i < len(cuts)-1; i++ {
+			sub := area
+			if vertical {
+				sub.top, sub.bottom = cuts[i], cuts[i+1]
+			} else {
+				sub.left, sub.right = cuts[i], cuts[i+1]
+			}
+			child, err := buildLayoutNode(rects, sub)
+			if err != nil {
+				return nil, err
+			}
+			child.Size = cuts[i+1] - cuts[i]
+			node.Children = append(node.Children, child)
+		}
+		return node, nil
+	}
+
+	sort.Slice(panes, func(i, j int) bool { return panes[i] < panes[j] })
+	return nil, fmt.Errorf("panes %q cannot be produced by tmux splits", string(panes))
+}
+
+// firstPane returns the top-left pane of a node, which is the pane that
+// occupies the whole node before any of its splits are made.
+func (n *layoutNode) firstPane() rune {
+	for len(n.Children) > 0 {
+		n = n.Children[0]
+	}
+	return n.Pane
+}
+
+// panes returns every pane letter in split order.
+func (n *layoutNode) panes() []rune {
+	if len(n.Children) == 0 {
+		return []rune{n.Pane}
+	}
+	var out []rune
+	for _, child := range n.Children {
+		out = append(out, child.panes()...)
+	}
+	return out
+}
+
+// besideOthers reports whether pane has panes to its left or right.
+func (n *layoutNode) besideOthers(pane rune) bool {
+	for _, child := range n.Children {
+		if strings.ContainsRune(string(child.panes()), pane) {
+			return !n.Vertical || child.besideOthers(pane)
+		}
+	}
+	return false
+}
+
+// splits returns the split-window sequence that builds the layout starting
+// from a single pane holding firstPane().
+func (n *layoutNode) splits() []layoutSplit {
+	var out []layoutSplit
+	if len(n.Children) == 0 {
+		return out
+	}
+
+	// Carve the siblings off one at a time, each split taking the share
+	// of the remaining space that belongs to the panes after it
+	remaining := 0
+	for _, child := range n.Children {
+		remaining += child.Size
+	}
+	for i := 1; i < len(n.Children); i++ {
+		remaining -= n.Children[i-1].Size
+		total := remaining + n.Children[i-1].Size
+		// A thin pane in a windowgram over 100 cells wide rounds down to
+		// 0%, which tmux rejects
+		percent := min(max(remaining*100/total, 1), 99)
+		out = append(out, layoutSplit{
+			Target:     n.Children[i-1].firstPane(),
+			New:        n.Children[i].firstPane(),
+			Horizontal: !n.Vertical,
+			Percent:    percent,
+		})
+	}
+
+	for _, child := range n.Children {
+		out = append(out, child.splits()...)
+	}
+	return out
+}
+
+// findLayout returns the layout with the given name, or the first layout.
+func findLayout(layouts []Layout, name string) (Layout, bool) {
+	for _, l := range layouts {
+		if l.Name == name {
+			return l, true
+		}
+	}
+	if name == "" && len(layouts) > 0 {
+		return layouts[0], true
+	}
+	return Layout{}, false
+}
+
+// renderLayoutDiagram draws the windowgram with box characters and puts a
+// label in each pane. Labels that do not fit are shortened or fall back to
+// the pane letter.
+func renderLayoutDiagram(windowgram string, labels map[rune]string) (string, error) {
+	if _, err := parseWindowgram(windowgram); err != nil {
+		return "", err
+	}
+
+	grid := windowgramGrid(windowgram)
+	rows, cols := len(grid), len(grid[0])
+
+	// Scale small windowgrams up so labels have room
+	sx := max(1, 40/cols)
+	sy := max(1, 8/rows)
+	height, width := rows*sy+1, cols*sx+1
+
+	cell := func(r, c int) rune {
+		if r < 0 || c < 0 || r >= rows || c >= cols {
+			return 0
+		}
+		return grid[r][c]
+	}
+
+	canvas := make([][]rune, height)
+	for y := range canvas {
+		canvas[y] = []rune(strings.Repeat(" ", width))
+	}
+
+	// Lattice points sit between grid cells; an edge leaves a point when the
+	// cells on either side of that edge belong to different panes
+	for r := 0; r <= rows; r++ {
+		for c := 0; c <= cols; c++ {
+			right := c < cols && cell(r-1, c) != cell(r, c)
+			left := c > 0 && cell(r-1, c-1) != cell(r, c-1)
+			down := r < rows && cell(r, c-1) != cell(r, c)
+			up := r > 0 && cell(r-1, c-1) != cell(r-1, c)
+
+			y, x := r*sy, c*sx
+			canvas[y][x] = boxJunction(up, down, left, right)
+			if right {
+				for i := 1; i < sx; i++ {
+					canvas[y][x+i] = '─'
+				}
+			}
+			if down {
+				for i := 1; i < sy; i++ {
+					canvas[y+i][x] = '│'
+				}
+			}
+		}
+	}
+
+	// Place labels in the top-left interior of each pane
+	placed := map[rune]bool{}
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			ch := grid[r][c]
+			if placed[ch] {
+				continue
+			}
+			placed[ch] = true
+
+			right, bottom := c, r
+			for right < cols && grid[r][right] == ch {
+				right++
+			}
+			for bottom < rows && grid[bottom][c] == ch {
+				bottom++
+			}
+			y := r*sy + 1
+			if y >= bottom*sy {
+				continue
+			}
+			room := (right-c)*sx - 1
+			label := []rune(labels[ch])
+			if len(label) > room && room >= 4 {
+				label = append(label[:room-1], '…')
+			}
+			if len(label) == 0 || len(label) > room {
+				label = []rune{ch}
+			}
+			copy(canvas[y][c*sx+1:], label)
+		}
+	}
+
+	lines := make([]string, height)
+	for y := range canvas {
+		lines[y] = string(canvas[y])
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func boxJunction(up, down, left, right bool) rune {
+	switch {
+	case up && down && left && right:
+		return '┼'
+	case up && down && left:
+		return '┤'
+	case up && down && right:
+		return '├'
+	case left && right && down:
+		return '┬'
+	case left && right && up:
+		return '┴'
+	case down && right:
+		return '┌'
+	case down && left:
+		return '┐'
+	case up && right:
+		return '└'
+	case up && left:
+		return '┘'
+	case up || down:
+		return '│'
+	case left || right:
+		return '─'
+	}
+	return ' '
+}
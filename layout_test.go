@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseWindowgram(t *testing.T) {
+	tests := []struct {
+		name       string
+		windowgram string
+		panes      string // split order, "" when parsing must fail
+		err        string
+	}{
+		{"default", defaultLayouts[0].Windowgram, "avtc", ""},
+		{"agent on top", defaultLayouts[2].Windowgram, "acvt", ""},
+		{"single pane", "aaa\naaa", "a", ""},
+		{"surrounding whitespace", "\n  aab  \n\n  aab\n", "ab", ""},
+		{"letters without a role", "xxyy\nzzyy", "xzy", ""},
+		{"empty", " \n\n", "", "empty"},
+		{"ragged rows", "aabb\naab", "", "line 2 is 3 wide, expected 4"},
+		{"not a rectangle", "aab\nabb", "", "is not a rectangle"},
+		{"split pane letter", "aba\naba", "", "is not a rectangle"},
+		{"pinwheel", "aab\ncdb\ncee", "", "cannot be produced by tmux splits"},
+		{"invalid character", "aa#\naa#", "", "invalid pane character '#'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseWindowgram(tt.windowgram)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(root.panes()); got != tt.panes {
+				t.Errorf("panes = %q, want %q", got, tt.panes)
+			}
+		})
+	}
+}
+
+func TestSplits(t *testing.T) {
+	tests := []struct {
+		name       string
+		windowgram string
+		want       []layoutSplit
+	}{
+		{"single pane", "aa\naa", nil},
+		{"default", defaultLayouts[0].Windowgram, []layoutSplit{
+			{Target: 'a', New: 'v', Horizontal: true, Percent: 79},
+			{Target: 'v', New: 't', Horizontal: false, Percent: 50},
+			{Target: 't', New: 'c', Horizontal: true, Percent: 47},
+		}},
+		{"three equal rows", "a\nb\nc", []layoutSplit{
+			{Target: 'a', New: 'b', Horizontal: false, Percent: 66},
+			{Target: 'b', New: 'c', Horizontal: false, Percent: 50},
+		}},
+		{"thin pane in a wide windowgram", strings.Repeat("a", 150) + "b", []layoutSplit{
+			{Target: 'a', New: 'b', Horizontal: true, Percent: 1},
+		}},
+		{"thin first pane in a wide windowgram", "a" + strings.Repeat("b", 150), []layoutSplit{
+			{Target: 'a', New: 'b', Horizontal: true, Percent: 99},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseWindowgram(tt.windowgram)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := root.splits()
+			if len(got) != len(tt.want) {
+				t.Fatalf("splits = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("split %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBesideOthers(t *testing.T) {
+	tests := []struct {
+		name       string
+		windowgram string
+		want       bool
+	}{
+		{"default", defaultLayouts[0].Windowgram, true},
+		{"single pane", "aa\naa", false},
+		{"full-width row", "aaa\nvvv", false},
+		{"nested column", "aaavvv\nttcccc", true},
+		{"right edge", "vva", true},
+		{"no vinw", "vt", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseWindowgram(tt.windowgram)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := root.besideOthers(paneVinw); got != tt.want {
+				t.Errorf("besideOthers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -55,9 +57,11 @@ var (
 )
 
 type fileEntry struct {
-	Name  string
-	Path  string
-	IsDir bool
+	Name    string
+	Path    string
+	IsDir   bool
+	Matches []int      // rune positions in Name matched by the search query
+	Meta    *entryMeta // nil until loaded for a visible entry
 }
 
 type state int
@@ -71,19 +75,49 @@ const (
 	stateNoobsHelp
 	stateInstallSelection
 	stateCommands
+	stateSessions
+	statePresets
+	stateImport
+)
+
+// Form fields in focus order
+const (
+	focusDirectory = iota
+	focusSession
+	focusWorktree
+	focusTerminal
+	focusAgent
+	focusLayout
+	focusLaunch
+)
+
+// What the directory chooser lists
+type browseMode int
+
+const (
+	browseFiles     browseMode = iota // contents of the current directory
+	browseRecent                      // launched directories by frecency
+	browseProjects                    // projects found under the project roots
+	browseBookmarks                   // bookmarked directories
 )
 
 type model struct {
 	width, height       int
 	currentState        state
+	config              Config
+	projectFile         string
+	projectDir          string // directory the form options were last set up for
 	menuCursor          int
 	animFrame           int
 	focusIndex          int
 	inputs              []textinput.Model
 	terminalCursor      int
 	agentCursor         int
+	checkedAgents       map[string]bool // multi-select; empty picks the agent under agentCursor
 	terminalOptions     []string
-	agentOptions        []string
+	agentOptions        []Agent
+	layouts             []Layout
+	layoutCursor        int
 	directory           string
 	files               []fileEntry
 	filteredFiles       []fileEntry
@@ -95,16 +129,13 @@ type model struct {
 	creatingNewDir      bool
 	searching           bool
 	err                 error
-	shouldLaunch        bool
-	launchDir           string
-	launchSession       string
-	launchTerminal      string
-	launchAgent         string
-	launchSessionID     string
-	launchCustomCmd     string
+	launchRun           launchProgress
+	confirmRecreate     bool
+	existing            sessionCheck // live session with the previewed name, looked up when the preview opens
 	commandsList        list.Model
 	workspaceCommands   []WorkspaceCommand
 	selectedCommandIdx  int
+	windows             []Window // extra windows opened after the layout
 	addingCommand       bool
 	commandNameInput    textinput.Model
 	commandCmdInput     textinput.Model
@@ -117,6 +148,49 @@ type model struct {
 	helpReady           bool
 	installMethodCursor int
 	installMethods      []installMethod
+	sessions            []sessionSummary
+	sessionsCursor      int
+	renamingSession     bool
+	sessionRenameInput  textinput.Model
+	confirmKillSession  bool
+	worktreeChanges     int // uncommitted changes in the worktree of the session being killed
+	attachTarget        string
+	presets             PresetsConfig
+	presetsCursor       int
+	confirmDeletePreset bool
+	savingPreset        bool
+	presetNameInput     textinput.Model
+	exporting           bool
+	exportPathInput     textinput.Model
+	showingPlan         bool
+	planViewport        viewport.Model
+	importInput         textinput.Model
+	importFiles         []string
+	importCursor        int
+	imported            *importResult // converted file waiting to be saved
+	browseMode          browseMode
+	recentDirs          []DirVisit
+	projects            []ProjectDir
+	scannedProjects     []ProjectDir
+	projectScan         <-chan ProjectDir
+	projectScanID       int
+	scanningProjects    bool
+	enteringPath        bool
+	pathInput           textinput.Model
+	pathCompletions     []string
+	showHidden          bool
+	metaPending         map[string]bool
+	agentVersions       map[string]string // agent binary -> version, "" until known
+	dirLoadID           int
+	dirLoading          bool
+	dirErr              error
+	dirMore             *os.File
+	spinner             spinner.Model
+	bookmarks           []Bookmark
+	addingBookmark      bool
+	bookmarkTarget      string
+	bookmarkAliasInput  textinput.Model
+	worktreeBranch      string // branch the session name was last derived from
 }
 
 type installMethod struct {
@@ -149,6 +223,18 @@ func initialModel() model {
 	searchInput.CharLimit = 100
 	searchInput.Width = 50
 
+	// Path input
+	pathInput := textinput.New()
+	pathInput.Placeholder = "~/code/project or $HOME/work"
+	pathInput.CharLimit = 4096
+	pathInput.Width = 50
+
+	// Bookmark alias input
+	bookmarkAliasInput := textinput.New()
+	bookmarkAliasInput.Placeholder = "alias (optional), e.g. api"
+	bookmarkAliasInput.CharLimit = 50
+	bookmarkAliasInput.Width = 40
+
 	// Session name input
 	sessionInput := textinput.New()
 	sessionInput.Placeholder = "my_session"
@@ -157,8 +243,16 @@ func initialModel() model {
 	sessionInput.Cursor.Style = cursorStyle
 	sessionInput.CharLimit = 256
 
+	// Worktree branch input
+	worktreeInput := textinput.New()
+	worktreeInput.Placeholder = "branch (optional)"
+	worktreeInput.Prompt = "Worktree:  "
+	worktreeInput.Cursor.Style = cursorStyle
+	worktreeInput.CharLimit = 256
+
 	// Load workspace commands
 	workspaceCommands, _ := loadWorkspaceCommands()
+	bookmarks, _ := loadBookmarks()
 
 	// Create list items from commands
 	items := make([]list.Item, len(workspaceCommands))
@@ -192,18 +286,50 @@ func initialModel() model {
 	commandDescInput.CharLimit = 100
 	commandDescInput.Width = 40
 
+	// Session rename input
+	sessionRenameInput := textinput.New()
+	sessionRenameInput.Placeholder = "new session name"
+	sessionRenameInput.CharLimit = 256
+	sessionRenameInput.Width = 40
+
+	// Preset name input
+	presetNameInput := textinput.New()
+	presetNameInput.Placeholder = "preset name"
+	presetNameInput.CharLimit = 100
+	presetNameInput.Width = 40
+
+	// Export path input
+	exportPathInput := textinput.New()
+	exportPathInput.Placeholder = "workspace.sh or workspace.yml"
+	exportPathInput.CharLimit = 4096
+	exportPathInput.Width = 60
+
+	// Import file input
+	importInput := textinput.New()
+	importInput.Placeholder = "~/.config/tmuxinator/project.yml"
+	importInput.CharLimit = 4096
+	importInput.Width = 50
+
 	m := model{
-		inputs:             []textinput.Model{sessionInput},
+		inputs:             []textinput.Model{sessionInput, worktreeInput},
 		terminalCursor:     0,
 		agentCursor:        0,
 		menuCursor:         0,
 		animFrame:          0,
 		currentState:       stateMenu,
+		config:             config,
 		terminalOptions:    config.TerminalOptions,
 		agentOptions:       config.AgentOptions,
+		layouts:            config.Layouts,
 		directory:          homeDir,
 		newDirInput:        newDirInput,
 		searchInput:        searchInput,
+		pathInput:          pathInput,
+		metaPending:        map[string]bool{},
+		agentVersions:      map[string]string{},
+		spinner:            spinner.New(spinner.WithSpinner(spinner.MiniDot), spinner.WithStyle(successStyle)),
+		bookmarks:          bookmarks,
+		bookmarkAliasInput: bookmarkAliasInput,
 		commandsList:       commandsList,
 		workspaceCommands:  workspaceCommands,
 		selectedCommandIdx: -1,
@@ -211,20 +337,35 @@ func initialModel() model {
 		commandNameInput:   commandNameInput,
 		commandCmdInput:    commandCmdInput,
 		commandDescInput:   commandDescInput,
+		sessionRenameInput: sessionRenameInput,
+		presetNameInput:    presetNameInput,
+		importInput:        importInput,
+		exportPathInput:    exportPathInput,
 		width:              80,
 		height:             24,
 	}
 
+	// Directories are read by commands; Init starts whichever load is pending
 	m.loadDirectory(homeDir)
+
+	// Start on recent projects once there are any
+	m.showRecent()
+	if len(m.files) == 0 {
+		m.loadDirectory(homeDir)
+	}
 	return m
 }
 
 func (m model) Init() tea.Cmd {
 	// Start with animation tick for smooth entrance
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		textinput.Blink,
 		tickAnimation(),
-	)
+	}
+	if m.dirLoading {
+		cmds = append(cmds, openDirectory(m.dirLoadID, m.directory, m.showHidden), m.spinner.Tick)
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -235,7 +376,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateViewport()
 		// Update commands list size
 		m.commandsList.SetSize(m.width-10, m.height-15)
-		return m, nil
+		m.planViewport.Width, m.planViewport.Height = m.width-8, m.height-8
+		return m, m.loadVisibleMeta()
 
 	case animationMsg:
 		// Advance animation frame
@@ -249,48 +391,261 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.statusMessage = msg
 		return m, nil
 
+	case launchWorktreeMsg, launchStepMsg, launchProbeMsg, launchHandoffMsg:
+		return m.updateLaunchProgress(msg)
+
+	case projectsFoundMsg:
+		cmd := m.handleProjectsFound(msg)
+		return m, tea.Batch(cmd, m.loadVisibleMeta())
+
+	case entryMetaMsg:
+		m.setEntryMeta(msg)
+		return m, nil
+
+	case agentVersionMsg:
+		m.agentVersions[msg.binary] = msg.version
+		return m, nil
+
+	case sessionCheckMsg:
+		m.existing = sessionCheck(msg)
+		return m, nil
+
+	case dirLoadedMsg:
+		m.handleDirLoaded(msg)
+		return m, m.loadVisibleMeta()
+
+	case spinner.TickMsg:
+		// Keep spinning only while a directory is being read or a launch
+		// step runs
+		if !m.dirLoading && !m.launchRunning() {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
 	case tea.KeyMsg:
-		// Delegate to appropriate update function based on current state
-		switch m.currentState {
-		case stateMenu:
-			return updateMenu(msg, m)
-		case stateForm:
-			return m.updateInput(msg)
-		case statePreview:
-			return m.updatePreview(msg)
-		case stateNoobs:
-			return updateNoobs(msg, m)
-		case stateNoobsHelp:
-			return updateNoobsHelp(msg, m)
-		case stateInstallSelection:
-			return updateInstallSelection(msg, m)
-		case stateCommands:
-			return updateCommands(msg, m)
+		next, cmd := m.updateKey(msg)
+		nm, ok := next.(model)
+		if !ok || (nm.currentState != stateForm && nm.currentState != statePreview) {
+			return next, cmd
+		}
+		// Look up the versions of newly picked agents for the preview
+		cmd = tea.Batch(cmd, nm.probeAgentVersions())
+		// Opening the preview, or acting on a live session, checks the
+		// session name again
+		recheck := m.currentState != statePreview
+		switch msg.String() {
+		case "a", "n", "r", "y":
+			recheck = true
+		}
+		if nm.currentState == statePreview && recheck {
+			cmd = tea.Batch(cmd, checkSession(nm.inputs[0].Value()))
 		}
+		if nm.currentState == stateForm && nm.focusIndex == focusDirectory {
+			// Fill in metadata for whatever scrolled into the directory chooser
+			cmd = tea.Batch(cmd, nm.loadVisibleMeta())
+		}
+		return nm, cmd
 	}
 
 	return m, nil
 }
 
+// updateKey delegates a key to the update function of the current state
+func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.currentState {
+	case stateMenu:
+		return updateMenu(msg, m)
+	case stateForm:
+		return m.updateInput(msg)
+	case statePreview:
+		return m.updatePreview(msg)
+	case stateLaunching:
+		return updateLaunching(msg, m)
+	case stateNoobs:
+		return updateNoobs(msg, m)
+	case stateNoobsHelp:
+		return updateNoobsHelp(msg, m)
+	case stateInstallSelection:
+		return updateInstallSelection(msg, m)
+	case stateCommands:
+		return updateCommands(msg, m)
+	case stateSessions:
+		return updateSessions(msg, m)
+	case statePresets:
+		return updatePresets(msg, m)
+	case stateImport:
+		return updateImport(msg, m)
+	}
+	return m, nil
+}
+
 func (m model) updatePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Preset name input takes all keys while open
+	if m.savingPreset {
+		switch msg.String() {
+		case "esc":
+			m.savingPreset = false
+			m.presetNameInput.Blur()
+		case "enter":
+			name := strings.TrimSpace(m.presetNameInput.Value())
+			if name == "" {
+				return m, nil
+			}
+			m.savingPreset = false
+			m.presetNameInput.Blur()
+			if err := savePreset(m.formPreset(name)); err != nil {
+				m.statusMessage = statusMsg{text: err.Error(), isError: true}
+				return m, nil
+			}
+			m.statusMessage = statusMsg{text: fmt.Sprintf("✓ Saved preset '%s'", name)}
+		default:
+			var cmd tea.Cmd
+			m.presetNameInput, cmd = m.presetNameInput.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	// Export path input takes all keys while open
+	if m.exporting {
+		return m.updateExportInput(msg)
+	}
+
+	if m.showingPlan {
+		return m.updatePlan(msg)
+	}
+
+	// Killing a live session needs a second keypress
+	if m.confirmRecreate {
+		switch msg.String() {
+		case "y":
+			m.confirmRecreate = false
+			return m.startLaunch(conflictRecreate)
+		case "ctrl+c":
+			return m, tea.Quit
+		default:
+			m.confirmRecreate = false
+		}
+		return m, nil
+	}
+
 	switch msg.String() {
 	case "ctrl+c", "q":
 		return m, tea.Quit
 
+	case "s":
+		m.savingPreset = true
+		m.statusMessage = statusMsg{}
+		m.presetNameInput.SetValue(m.inputs[0].Value())
+		m.presetNameInput.CursorEnd()
+		return m, m.presetNameInput.Focus()
+
+	case "e":
+		if _, err := parseWindowgram(m.layouts[m.layoutCursor].Windowgram); err == nil {
+			return m.startExport()
+		}
+
+	case "d":
+		if _, err := parseWindowgram(m.layouts[m.layoutCursor].Windowgram); err == nil {
+			return m.openPlan()
+		}
+
 	case "esc":
 		m.currentState = stateForm
+		m.statusMessage = statusMsg{}
 		// Focus back on session name input when returning
-		m.focusIndex = 1
-		m.inputs[0].Focus()
-		m.inputs[0].PromptStyle = focusedStyle
-		m.inputs[0].TextStyle = focusedStyle
+		m.focusIndex = focusSession
+		m.focusInputs()
 		return m, nil
 
 	case "enter", "l":
-		// Check dependencies and session existence before launching
+		if sessionExists(m.inputs[0].Value()) {
+			// An existing session needs an explicit choice below
+			return m, nil
+		}
+		return m.startLaunch(conflictFail)
+
+	case "a":
+		if sessionExists(m.inputs[0].Value()) {
+			return m.startLaunch(conflictAttach)
+		}
+
+	case "n":
+		if sessionExists(m.inputs[0].Value()) {
+			return m.startLaunch(conflictSuffix)
+		}
+
+	case "r":
+		if sessionExists(m.inputs[0].Value()) {
+			m.confirmRecreate = true
+		}
+	}
+
+	return m, nil
+}
+
+// sessionCheck is what the preview shows about a live session with the
+// chosen name. It is looked up in the background so rendering never runs
+// tmux.
+type sessionCheck struct {
+	name   string
+	exists bool
+	info   *sessionInfo // nil when the session could not be read
+	next   string       // first free name of the form name-2, name-3, ...
+}
+
+type sessionCheckMsg sessionCheck
+
+// checkSession looks up whether a session exists, what runs in it and the
+// name a new session would get instead.
+func checkSession(name string) tea.Cmd {
+	return func() tea.Msg {
+		check := sessionCheckMsg{name: name}
+		if !sessionExists(name) {
+			return check
+		}
+		check.exists = true
+		if info, err := describeSession(name); err == nil {
+			check.info = &info
+		}
+		check.next = nextSessionName(name)
+		return check
+	}
+}
+
+// agentMark returns the marker and style for an agent option: a radio
+// button while one agent is picked, a checkbox in multi-select.
+func (m model) agentMark(i int) (string, lipgloss.Style) {
+	style := radioUnselectedStyle
+	if m.agentCursor == i && m.focusIndex == focusAgent {
+		style = radioSelectedStyle
+	}
+
+	if len(m.checkedAgents) > 0 {
+		if m.checkedAgents[m.agentOptions[i].Name] {
+			return "■", style
+		}
+		return "□", style
+	}
+	if m.agentCursor == i {
+		return "●", style
+	}
+	return "○", style
+}
+
+// startLaunch builds the workspace from the form and hands it to
+// beginLaunch, which runs the plan step by step on the launching screen.
+// The TUI only quits to attach once every step is done.
+func (m model) startLaunch(onConflict string) (tea.Model, tea.Cmd) {
+	layout := m.layouts[m.layoutCursor]
+
+	// Reusing an existing session needs none of the launch prerequisites
+	if onConflict != conflictAttach {
 		deps := checkDependencies(
 			m.terminalOptions[m.terminalCursor],
-			m.agentOptions[m.agentCursor],
+			m.selectedAgents(),
 		)
 
 		if !allDependenciesAvailable(deps) {
@@ -298,30 +653,51 @@ func (m model) updatePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-		sessionName := m.inputs[0].Value()
-		if sessionExists(sessionName) {
-			// Don't launch if session already exists
+		if _, err := parseWindowgram(layout.Windowgram); err != nil {
+			// Don't launch a layout tmux cannot build
 			return m, nil
 		}
+	}
 
-		// Store launch parameters and quit
-		// The actual launch will happen after the TUI exits
-		m.shouldLaunch = true
-		m.launchDir = m.directory
-		m.launchSession = sessionName
-		m.launchTerminal = m.terminalOptions[m.terminalCursor]
-		m.launchAgent = m.agentOptions[m.agentCursor]
-		m.launchSessionID = generateSessionID(m.directory)
+	// A branch that cannot be checked out keeps the TUI open. The
+	// worktree itself is created as the first step on the launching screen;
+	// attaching only needs the session name
+	spec, worktree, err := m.formSpec()
+	if err != nil && onConflict != conflictAttach {
+		m.statusMessage = statusMsg{text: err.Error(), isError: true}
+		return m, nil
+	}
 
-		// Include custom command if selected
-		if m.selectedCommandIdx >= 0 && m.selectedCommandIdx < len(m.workspaceCommands) {
-			m.launchCustomCmd = m.workspaceCommands[m.selectedCommandIdx].Command
-		}
+	return m.beginLaunch(spec, worktree, onConflict)
+}
 
-		return m, tea.Quit
+// formSpec is the workspace the form would launch, with the worktree
+// planned but not created yet.
+func (m model) formSpec() (workspaceSpec, *worktreePlan, error) {
+	spec := workspaceSpec{
+		Dir:       m.directory,
+		Session:   m.inputs[0].Value(),
+		Terminal:  m.terminalOptions[m.terminalCursor],
+		Agents:    m.selectedAgents(),
+		Layout:    m.layouts[m.layoutCursor],
+		PaneStart: m.config.PaneStart,
+		Windows:   m.windows,
+	}
+	if m.selectedCommandIdx >= 0 && m.selectedCommandIdx < len(m.workspaceCommands) {
+		spec.CustomCmd = m.workspaceCommands[m.selectedCommandIdx].Command
 	}
 
-	return m, nil
+	var worktree *worktreePlan
+	if branch := strings.TrimSpace(m.inputs[1].Value()); branch != "" {
+		plan, err := planWorktree(m.directory, branch, m.config.WorktreeDir)
+		if err != nil {
+			return spec, nil, err
+		}
+		worktree = &plan
+		spec.Dir, spec.Worktree = plan.LaunchDir, plan.Path
+	}
+	spec.SessionID = generateSessionID(spec.Dir)
+	return spec, worktree, nil
 }
 
 func (m model) View() string {
@@ -333,6 +709,8 @@ func (m model) View() string {
 		return m.viewInput()
 	case statePreview:
 		return m.viewPreview()
+	case stateLaunching:
+		return viewLaunching(m)
 	case stateNoobs:
 		return viewNoobs(m)
 	case stateNoobsHelp:
@@ -341,6 +719,12 @@ func (m model) View() string {
 		return viewInstallSelection(m)
 	case stateCommands:
 		return viewCommands(m)
+	case stateSessions:
+		return viewSessions(m)
+	case statePresets:
+		return viewPresets(m)
+	case stateImport:
+		return viewImport(m)
 	default:
 		return "Unknown state"
 	}
@@ -349,8 +733,8 @@ func (m model) View() string {
 func (m model) viewInput() string {
 	var s strings.Builder
 
-	// Directory browser when focusIndex == 0
-	if m.focusIndex == 0 {
+	// Directory browser when focusIndex == focusDirectory
+	if m.focusIndex == focusDirectory {
 		// Content width: window - margin - border - padding
 		// = (m.width - 2) - 2 - 4 = m.width - 8
 		contentWidth := m.width - 8
@@ -372,7 +756,25 @@ func (m model) viewInput() string {
 		if len(displayDir) > contentWidth-10 {
 			displayDir = "..." + displayDir[len(displayDir)-(contentWidth-13):]
 		}
-		s.WriteString(blurredStyle.Render("Current: ") + successStyle.Render(displayDir))
+		if m.browseMode == browseRecent {
+			s.WriteString(blurredStyle.Render("Recent projects (most launched first)"))
+		} else if m.browseMode == browseBookmarks {
+			s.WriteString(blurredStyle.Render("Bookmarks"))
+		} else if m.browseMode == browseProjects {
+			status := fmt.Sprintf("%d found", len(m.projects))
+			if m.scanningProjects {
+				status = fmt.Sprintf("scanning… %d found", len(m.projects))
+			}
+			s.WriteString(blurredStyle.Render("Projects ") + successStyle.Render(status))
+		} else {
+			s.WriteString(blurredStyle.Render("Current: ") + successStyle.Render(displayDir))
+			if m.dirLoading {
+				s.WriteString(" " + m.spinner.View() + blurredStyle.Render(" loading…"))
+			}
+			if m.dirErr != nil {
+				s.WriteString("\n" + errorStyle.Render(dirErrorText(m.dirErr)))
+			}
+		}
 		s.WriteString("\n\n")
 
 		if m.creatingNewDir {
@@ -382,6 +784,31 @@ func (m model) viewInput() string {
 		} else if m.searching {
 			s.WriteString(sectionTitleStyle.Render("Search") + "\n")
 			s.WriteString(m.searchInput.View() + "\n")
+		} else if m.addingBookmark {
+			target := m.bookmarkTarget
+			if strings.HasPrefix(target, homeDir) {
+				target = "~" + strings.TrimPrefix(target, homeDir)
+			}
+			s.WriteString(sectionTitleStyle.Render("Bookmark") + "\n")
+			s.WriteString(blurredStyle.Render("Dir: "+target) + "\n")
+			s.WriteString("Alias: " + m.bookmarkAliasInput.View() + "\n")
+		} else if m.enteringPath {
+			s.WriteString(sectionTitleStyle.Render("Go to Path") + "\n")
+			s.WriteString(m.pathInput.View() + "\n")
+			if status, ok := m.pathStatus(); ok {
+				s.WriteString(successStyle.Render(status) + "\n")
+			} else if status != "" {
+				s.WriteString(errorStyle.Render(status) + "\n")
+			} else {
+				s.WriteString("\n")
+			}
+			for i, name := range m.pathCompletions {
+				if i == maxPathCompletions {
+					s.WriteString(blurredStyle.Render(fmt.Sprintf("  … %d more", len(m.pathCompletions)-i)) + "\n")
+					break
+				}
+				s.WriteString(folderStyle.Render("  "+name+"/") + "\n")
+			}
 		}
 
 		// Separator with consistent width
@@ -389,23 +816,50 @@ func (m model) viewInput() string {
 		s.WriteString("\n" + sepStyle.Render(strings.Repeat("─", contentWidth)) + "\n\n")
 
 		// Show files and directories (viewport)
+		now := time.Now()
 		for i := m.viewportStart; i < m.viewportEnd; i++ {
 			if i >= len(m.filteredFiles) {
 				break
 			}
 			entry := m.filteredFiles[i]
-			line := entry.Name
+			name := entry.Name
+
+			// Right-hand column: git branch, symlink target, modified time
+			column := metaColumn(entry.Meta, now)
+			if runes := []rune(column); len(runes) > contentWidth/3 {
+				column = "…" + string(runes[len(runes)-contentWidth/3+1:])
+			}
 
 			// Truncate long filenames
 			maxNameLen := contentWidth - 6
-			if len(line) > maxNameLen {
-				line = line[:maxNameLen-3] + "..."
+			if column != "" {
+				maxNameLen -= lipgloss.Width(column) + 2
+			}
+			if runes := []rune(name); len(runes) > maxNameLen {
+				name = string(runes[:maxNameLen-3]) + "..."
 			}
 
-			if entry.IsDir {
-				line = folderStyle.Render("📂 " + line)
+			var line string
+			if m.browseMode == browseProjects {
+				line = folderStyle.Render("📂 ") + highlightMatches(name, entry.Matches, folderStyle) +
+					blurredStyle.Render("  "+strings.Join(m.projectTags(entry.Path), " • "))
+			} else if m.browseMode == browseRecent {
+				line = folderStyle.Render("📂 ") + highlightMatches(name, entry.Matches, folderStyle)
+				for _, visit := range m.recentDirs {
+					if visit.Path == entry.Path {
+						line += blurredStyle.Render(fmt.Sprintf("  %d× • %s", visit.Count, timeAgo(visit.LastUsed, now)))
+						break
+					}
+				}
+			} else if entry.IsDir {
+				line = folderStyle.Render("📂 ") + highlightMatches(name, entry.Matches, folderStyle)
 			} else {
-				line = fileStyle.Render("   " + line)
+				line = fileStyle.Render("   ") + highlightMatches(name, entry.Matches, fileStyle)
+			}
+
+			if column != "" {
+				gap := max(1, contentWidth-2-lipgloss.Width(line)-lipgloss.Width(column))
+				line += strings.Repeat(" ", gap) + blurredStyle.Render(column)
 			}
 
 			if i == m.cursor {
@@ -415,12 +869,27 @@ func (m model) viewInput() string {
 			}
 			s.WriteString(line + "\n")
 		}
+		if m.browseMode == browseFiles && m.dirMore != nil {
+			s.WriteString(blurredStyle.Render(fmt.Sprintf("  … %d entries loaded • m: show more", len(m.files))) + "\n")
+		}
 
 		s.WriteString("\n" + sepStyle.Render(strings.Repeat("─", contentWidth)) + "\n\n")
 
+		if m.statusMessage.text != "" {
+			statusStyle := successStyle
+			if m.statusMessage.isError {
+				statusStyle = errorStyle
+			}
+			s.WriteString(statusStyle.Render(m.statusMessage.text) + "\n")
+		}
+
 		// Help based on mode
 		if m.creatingNewDir {
 			s.WriteString(helpStyle.Render("enter: create • esc: cancel"))
+		} else if m.addingBookmark {
+			s.WriteString(helpStyle.Render("enter: save • esc: cancel"))
+		} else if m.enteringPath {
+			s.WriteString(helpStyle.Render("tab: complete • enter: go • esc: cancel • ~ and $VARS expand"))
 		} else if m.searching {
 			totalFiles := len(m.filteredFiles)
 			if totalFiles == 0 {
@@ -428,8 +897,26 @@ func (m model) viewInput() string {
 			} else {
 				s.WriteString(helpStyle.Render(fmt.Sprintf("(%d/%d) ↑↓: navigate • enter: explore • tab: select • esc: exit", m.cursor+1, totalFiles)))
 			}
+		} else if m.browseMode == browseRecent {
+			if len(m.filteredFiles) == 0 {
+				s.WriteString(helpStyle.Render("No recent projects yet • r: browse files • p: projects"))
+			} else {
+				s.WriteString(helpStyle.Render(fmt.Sprintf("(%d/%d) ↑↓/jk: nav • →: explore • enter/tab: select • s: search • r: browse files • p: projects", m.cursor+1, len(m.filteredFiles))))
+			}
+		} else if m.browseMode == browseBookmarks {
+			if len(m.filteredFiles) == 0 {
+				s.WriteString(helpStyle.Render("No bookmarks yet - press b on a directory • B: browse files"))
+			} else {
+				s.WriteString(helpStyle.Render(fmt.Sprintf("(%d/%d) ↑↓/jk: nav • →: explore • enter/tab: select • s: search • b: alias • x: remove • B: browse files", m.cursor+1, len(m.filteredFiles))))
+			}
+		} else if m.browseMode == browseProjects {
+			if len(m.filteredFiles) == 0 && !m.scanningProjects {
+				s.WriteString(helpStyle.Render("No projects found - set project_roots in config.json • p: browse files • r: recent"))
+			} else {
+				s.WriteString(helpStyle.Render(fmt.Sprintf("(%d/%d) ↑↓/jk: nav • →: explore • enter/tab: select • s: search • p: browse files • r: recent", m.cursor+1, len(m.filteredFiles))))
+			}
 		} else {
-			s.WriteString(helpStyle.Render(fmt.Sprintf("(%d/%d) ↑↓/jk: nav • →: explore • ←: up • enter/tab: select • s: search • n: new", m.cursor+1, len(m.filteredFiles))))
+			s.WriteString(helpStyle.Render(fmt.Sprintf("(%d/%d) ↑↓/jk: nav • →: explore • ←: up • enter/tab: select • s: search • g: go to path • n: new • b: bookmark • B: bookmarks • r: recent • p: projects • .: hidden", m.cursor+1, len(m.filteredFiles))))
 		}
 
 		// Full-height container (leave 1 char margin for border on each side)
@@ -454,16 +941,28 @@ func (m model) viewInput() string {
 		displayDir = "~" + strings.TrimPrefix(displayDir, homeDir)
 	}
 	s.WriteString(blurredStyle.Render("Directory: ") + successStyle.Render(displayDir))
-	s.WriteString("\n\n")
+	s.WriteString("\n")
+	if m.projectFile != "" {
+		s.WriteString(blurredStyle.Render("Project:   ") + successStyle.Render(projectFileName))
+		s.WriteString("\n")
+	}
+	s.WriteString("\n")
 
 	// Session name input
 	s.WriteString(m.inputs[0].View())
 	s.WriteString("\n\n")
 
+	// Worktree branch input
+	s.WriteString(m.inputs[1].View())
+	if m.focusIndex == focusWorktree {
+		s.WriteString("\n" + blurredStyle.Render("           New or existing branch, checked out in its own git worktree"))
+	}
+	s.WriteString("\n\n")
+
 	// Terminal selection
 	terminalLabel := "Terminal:"
 	terminalHint := ""
-	if m.focusIndex == 2 {
+	if m.focusIndex == focusTerminal {
 		terminalLabel = focusedLabelStyle.Render("› Terminal:")
 		terminalHint = "    " + blurredStyle.Render("(Press 'c' to add custom commands)")
 	} else {
@@ -481,7 +980,7 @@ func (m model) viewInput() string {
 
 			if m.terminalCursor == i {
 				cursor = "●"
-				if m.focusIndex == 2 {
+				if m.focusIndex == focusTerminal {
 					style = radioSelectedStyle
 				}
 			}
@@ -515,7 +1014,7 @@ func (m model) viewInput() string {
 
 			if m.terminalCursor == i {
 				cursor = "●"
-				if m.focusIndex == 2 {
+				if m.focusIndex == focusTerminal {
 					style = radioSelectedStyle
 				}
 			}
@@ -535,7 +1034,7 @@ func (m model) viewInput() string {
 
 	// Agent selection
 	agentLabel := "Coding Agent:"
-	if m.focusIndex == 3 {
+	if m.focusIndex == focusAgent {
 		agentLabel = focusedLabelStyle.Render("› Coding Agent:")
 	} else {
 		agentLabel = blurredLabelStyle.Render("  Coding Agent:")
@@ -547,17 +1046,9 @@ func (m model) viewInput() string {
 		// Two columns
 		var col1, col2 strings.Builder
 		for i, opt := range m.agentOptions {
-			cursor := "○"
-			style := radioUnselectedStyle
+			cursor, style := m.agentMark(i)
 
-			if m.agentCursor == i {
-				cursor = "●"
-				if m.focusIndex == 3 {
-					style = radioSelectedStyle
-				}
-			}
-
-			line := fmt.Sprintf("  %s %s", style.Render(cursor), style.Render(opt))
+			line := fmt.Sprintf("  %s %s", style.Render(cursor), style.Render(opt.Name))
 			if i%2 == 0 {
 				col1.WriteString(line + "\n")
 			} else {
@@ -574,19 +1065,41 @@ func (m model) viewInput() string {
 	} else {
 		// Single column
 		for i, opt := range m.agentOptions {
-			cursor := "○"
-			style := radioUnselectedStyle
+			cursor, style := m.agentMark(i)
 
-			if m.agentCursor == i {
-				cursor = "●"
-				if m.focusIndex == 3 {
-					style = radioSelectedStyle
-				}
-			}
+			s.WriteString(fmt.Sprintf("    %s %s\n", style.Render(cursor), style.Render(opt.Name)))
+		}
+	}
+	if m.focusIndex == focusAgent {
+		s.WriteString(blurredStyle.Render("    space: run several agents side by side") + "\n")
+	}
+
+	s.WriteString("\n")
+
+	// Layout selection
+	layoutLabel := "Layout:"
+	if m.focusIndex == focusLayout {
+		layoutLabel = focusedLabelStyle.Render("› Layout:")
+	} else {
+		layoutLabel = blurredLabelStyle.Render("  Layout:")
+	}
+	s.WriteString(layoutLabel + "\n")
+
+	var layoutLine []string
+	for i, l := range m.layouts {
+		cursor := "○"
+		style := radioUnselectedStyle
 
-			s.WriteString(fmt.Sprintf("    %s %s\n", style.Render(cursor), style.Render(opt)))
+		if m.layoutCursor == i {
+			cursor = "●"
+			if m.focusIndex == focusLayout {
+				style = radioSelectedStyle
+			}
 		}
+
+		layoutLine = append(layoutLine, fmt.Sprintf("%s %s", style.Render(cursor), style.Render(l.Name)))
 	}
+	s.WriteString("    " + strings.Join(layoutLine, "   ") + "\n")
 
 	s.WriteString("\n")
 
@@ -600,9 +1113,18 @@ func (m model) viewInput() string {
 		s.WriteString("\n")
 	}
 
+	// Extra windows opened after the layout
+	if len(m.windows) > 0 {
+		s.WriteString(blurredLabelStyle.Render("  Windows:") + "\n")
+		for _, w := range m.windows {
+			s.WriteString(fmt.Sprintf("    %s %s\n", successStyle.Render("+ "+w.Name), blurredStyle.Render(w.Command)))
+		}
+		s.WriteString("\n")
+	}
+
 	// Launch button
 	buttonStyle := blurredStyle
-	if m.focusIndex == 4 {
+	if m.focusIndex == focusLaunch {
 		buttonStyle = focusedStyle
 	}
 	s.WriteString(buttonStyle.Render("[ Preview & Launch ]"))
@@ -627,6 +1149,10 @@ func (m model) viewInput() string {
 }
 
 func (m model) viewPreview() string {
+	if m.showingPlan {
+		return m.viewPlan()
+	}
+
 	var s strings.Builder
 
 	// Title at top
@@ -634,12 +1160,16 @@ func (m model) viewPreview() string {
 	s.WriteString("\n\n")
 
 	sessionName := m.inputs[0].Value()
-	sessionAlreadyExists := sessionExists(sessionName)
+	sessionAlreadyExists := m.existing.exists && m.existing.name == sessionName
 
+	agents := m.selectedAgents()
 	deps := checkDependencies(
 		m.terminalOptions[m.terminalCursor],
-		m.agentOptions[m.agentCursor],
+		agents,
 	)
+	for i := range deps {
+		deps[i].Version = m.agentVersions[deps[i].Name]
+	}
 
 	// Get custom command if selected
 	customCmd := ""
@@ -647,14 +1177,26 @@ func (m model) viewPreview() string {
 		customCmd = m.workspaceCommands[m.selectedCommandIdx].Command
 	}
 
-	// Layout diagram
-	s.WriteString(sectionTitleStyle.Render("Pane Layout") + "\n")
-	layoutBox := `┌─────┬──────────────┐
-│vinw │ vinw-viewer  │
-│     ├──────┬───────┤
-│     │ term │ agent │
-└─────┴──────┴───────┘`
-	s.WriteString(blurredStyle.Render(layoutBox))
+	// Layout diagram, drawn from the same windowgram the launcher uses
+	layout := m.layouts[m.layoutCursor]
+	placed, extraAgents := map[rune]Agent{}, agents
+	if root, err := parseWindowgram(layout.Windowgram); err == nil {
+		placed, extraAgents = placeAgents(root, agents)
+	}
+	s.WriteString(sectionTitleStyle.Render("Pane Layout") + "  " + blurredStyle.Render(layout.Name) + "\n")
+	layoutBox, layoutErr := renderLayoutDiagram(layout.Windowgram, paneLabels(
+		m.terminalOptions[m.terminalCursor],
+		placed,
+		customCmd,
+	))
+	if layoutErr != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("  ⚠ Invalid layout: %v", layoutErr)))
+	} else {
+		s.WriteString(blurredStyle.Render(layoutBox))
+		if windows := append(agentNames(extraAgents), windowNames(m.windows)...); len(windows) > 0 {
+			s.WriteString("\n" + blurredStyle.Render("  + windows: "+strings.Join(windows, ", ")))
+		}
+	}
 	s.WriteString("\n\n")
 
 	// Configuration
@@ -667,18 +1209,48 @@ func (m model) viewPreview() string {
 	s.WriteString(sectionTitleStyle.Render("Configuration") + "\n")
 	s.WriteString(fmt.Sprintf("  %s %s\n", blurredStyle.Render("Directory:"), successStyle.Render(displayDir)))
 	s.WriteString(fmt.Sprintf("  %s %s\n", blurredStyle.Render("Session:"), successStyle.Render(sessionName)))
+	if branch := strings.TrimSpace(m.inputs[1].Value()); branch != "" {
+		s.WriteString(fmt.Sprintf("  %s %s\n", blurredStyle.Render("Worktree:"), successStyle.Render(branch)))
+	}
 
 	terminalDisplay := m.terminalOptions[m.terminalCursor]
 	if customCmd != "" {
 		terminalDisplay = "custom"
 	}
 	s.WriteString(fmt.Sprintf("  %s %s\n", blurredStyle.Render("Terminal:"), successStyle.Render(terminalDisplay)))
-	s.WriteString(fmt.Sprintf("  %s %s\n", blurredStyle.Render("Agent:"), successStyle.Render(m.agentOptions[m.agentCursor])))
+	if len(agents) == 0 {
+		s.WriteString(fmt.Sprintf("  %s %s\n", blurredStyle.Render("Agent:"), successStyle.Render("none")))
+	}
+	for _, agent := range agents {
+		where := "own window"
+		for pane, a := range placed {
+			if a.Name == agent.Name {
+				where = fmt.Sprintf("pane [%c]", pane)
+			}
+		}
+		s.WriteString(fmt.Sprintf("  %s %s %s\n", blurredStyle.Render("Agent:"), successStyle.Render(agent.Name), blurredStyle.Render("→ "+where)))
+		// The exact command line typed into the agent pane
+		s.WriteString(fmt.Sprintf("  %s %s\n", blurredStyle.Render("Runs:"), successStyle.Render(agent.commandLine())))
+	}
+	for _, w := range m.windows {
+		s.WriteString(fmt.Sprintf("  %s %s %s\n", blurredStyle.Render("Window:"), successStyle.Render(w.Name), blurredStyle.Render("→ "+w.Command)))
+		if w.Dir != "" {
+			s.WriteString(fmt.Sprintf("  %s %s\n", blurredStyle.Render("In:"), successStyle.Render(w.Dir)))
+		}
+	}
 
-	// Session conflict warning
+	// Session conflict: show what is running before offering to reuse it
 	if sessionAlreadyExists {
 		s.WriteString("\n")
-		s.WriteString(errorStyle.Render("  ⚠ Session exists! Choose different name"))
+		s.WriteString(errorStyle.Render("  ⚠ Session '"+sessionName+"' already exists") + "\n")
+		if info := m.existing.info; info != nil {
+			existingDir := info.Path
+			if strings.HasPrefix(existingDir, homeDir) {
+				existingDir = "~" + strings.TrimPrefix(existingDir, homeDir)
+			}
+			s.WriteString(fmt.Sprintf("  %s %s\n", blurredStyle.Render("Directory:"), existingDir))
+			s.WriteString(fmt.Sprintf("  %s %s", blurredStyle.Render("Panes:"), strings.Join(info.PaneCommands, ", ")))
+		}
 	}
 
 	s.WriteString("\n\n")
@@ -687,24 +1259,59 @@ func (m model) viewPreview() string {
 	s.WriteString(sectionTitleStyle.Render("Dependencies") + "\n")
 	for _, dep := range deps {
 		if dep.Available {
-			s.WriteString(fmt.Sprintf("  %s %s\n", successStyle.Render("✓"), dep.Name))
+			line := fmt.Sprintf("  %s %s", successStyle.Render("✓"), dep.Name)
+			if dep.Version != "" {
+				line += " " + blurredStyle.Render(dep.Version)
+			}
+			s.WriteString(line + "\n")
 		} else {
-			s.WriteString(fmt.Sprintf("  %s %s\n", errorStyle.Render("✗"), dep.Name))
+			line := fmt.Sprintf("  %s %s", errorStyle.Render("✗"), dep.Name)
+			if dep.InstallHint != "" {
+				line += " " + blurredStyle.Render("→ "+dep.InstallHint)
+			}
+			s.WriteString(line + "\n")
 		}
 	}
 
 	s.WriteString("\n")
 
-	canLaunch := allDependenciesAvailable(deps) && !sessionAlreadyExists
+	canLaunch := allDependenciesAvailable(deps) && !sessionAlreadyExists && layoutErr == nil
+
+	if m.statusMessage.text != "" {
+		statusStyle := successStyle.Bold(true)
+		if m.statusMessage.isError {
+			statusStyle = errorStyle.Bold(true)
+		}
+		s.WriteString(statusStyle.Render(m.statusMessage.text) + "\n\n")
+	}
 
-	if canLaunch {
+	if m.savingPreset {
+		s.WriteString(sectionTitleStyle.Render("Save as Preset") + "\n")
+		s.WriteString(m.presetNameInput.View() + "\n\n")
+		s.WriteString(helpStyle.Render("enter: save • esc: cancel"))
+	} else if m.exporting {
+		s.WriteString(sectionTitleStyle.Render("Export") + "\n")
+		s.WriteString(m.exportPathInput.View() + "\n")
+		s.WriteString(blurredStyle.Render("  .sh: shell script of tmux commands • .yml: tmuxinator project") + "\n\n")
+		s.WriteString(helpStyle.Render("enter: write • esc: cancel"))
+	} else if canLaunch {
 		s.WriteString(successStyle.Render("✓ Ready to launch!"))
 		s.WriteString("\n\n")
 		s.WriteString(focusedStyle.Render("[ Launch Session ]"))
 		s.WriteString("\n\n")
-		s.WriteString(helpStyle.Render("enter/l: launch • esc: back • q: quit"))
+		s.WriteString(helpStyle.Render("enter/l: launch • s: save as preset • e: export • d: dry run • esc: back • q: quit"))
+	} else if sessionAlreadyExists && m.confirmRecreate {
+		s.WriteString(errorStyle.Render("⚠ Kill '" + sessionName + "' and everything running in it?"))
+		s.WriteString("\n\n")
+		s.WriteString(helpStyle.Render("y: kill & recreate • any other key: cancel"))
 	} else if sessionAlreadyExists {
-		s.WriteString(helpStyle.Render("esc: back to change name • q: quit"))
+		help := "a: attach • esc: back to change name • q: quit"
+		if allDependenciesAvailable(deps) && layoutErr == nil {
+			help = fmt.Sprintf("a: attach • n: new as %s • r: kill & recreate • s: save as preset • esc: back • q: quit", m.existing.next)
+		}
+		s.WriteString(helpStyle.Render(help))
+	} else if layoutErr != nil {
+		s.WriteString(helpStyle.Render("esc: back to pick another layout • q: quit"))
 	} else {
 		tmuxMissing := false
 		for _, dep := range deps {
@@ -739,6 +1346,11 @@ func (m model) viewPreview() string {
 }
 
 func main() {
+	// Subcommands run headless and never open the alt screen
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
 	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
 	finalModel, err := p.Run()
 	if err != nil {
@@ -746,18 +1358,12 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Check if we should launch a session after the TUI exits
-	if m, ok := finalModel.(model); ok && m.shouldLaunch {
-		if err := launchTmuxSession(
-			m.launchDir,
-			m.launchSession,
-			m.launchTerminal,
-			m.launchAgent,
-			m.launchSessionID,
-			m.launchCustomCmd,
-		); err != nil {
+	// Attach to a session picked in the session manager
+	if m, ok := finalModel.(model); ok && m.attachTarget != "" {
+		if err := attachSession(m.attachTarget); err != nil {
 			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
+		return
 	}
 }
@@ -11,6 +11,8 @@ type menuChoice int
 
 const (
 	menuNewWorkspace menuChoice = iota
+	menuPresets
+	menuSessions
 	menuNoobs
 )
 
@@ -19,6 +21,8 @@ var menuItems = []struct {
 	description string
 }{
 	{"Start New Workspace", "→ Configure directory, terminal, and agent"},
+	{"Recent & Presets", "→ Relaunch a saved or recent workspace"},
+	{"Manage Sessions", "→ Attach, rename, or kill running workspaces"},
 	{"TMUX Noobs", "→ Setup tmux configuration and tools"},
 }
 
@@ -37,6 +41,12 @@ func updateMenu(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
 				// Go to form state
 				m.currentState = stateForm
 				return m, nil
+			case menuPresets:
+				// Go to recent & presets
+				return openPresets(m), nil
+			case menuSessions:
+				// Go to session manager
+				return openSessions(m), nil
 			case menuNoobs:
 				// Go to noobs setup state
 				m.currentState = stateNoobs
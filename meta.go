@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxLinkWidth caps how much of a symlink target is shown.
+const maxLinkWidth = 24
+
+// entryMeta is the extra detail shown next to a directory chooser entry.
+// It is loaded in the background for visible entries only.
+type entryMeta struct {
+	ModTime    time.Time
+	LinkTarget string // symlink destination, "" for regular entries
+	IsRepo     bool
+	Branch     string // current branch, or a short commit for a detached HEAD
+	Dirty      bool   // tracked files have uncommitted changes
+}
+
+// entryMetaMsg delivers the metadata for one entry
+type entryMetaMsg struct {
+	path string
+	meta entryMeta
+}
+
+// loadVisibleMeta starts loading metadata for the visible entries that do
+// not have it yet. Each entry loads separately so slow git calls do not
+// hold back the rest.
+func (m *model) loadVisibleMeta() tea.Cmd {
+	var cmds []tea.Cmd
+	for i := m.viewportStart; i < m.viewportEnd && i < len(m.filteredFiles); i++ {
+		entry := m.filteredFiles[i]
+		if entry.Meta != nil || entry.Name == ".." || m.metaPending[entry.Path] {
+			continue
+		}
+		m.metaPending[entry.Path] = true
+		cmds = append(cmds, loadEntryMeta(entry.Path, entry.IsDir))
+	}
+	return tea.Batch(cmds...)
+}
+
+func loadEntryMeta(path string, isDir bool) tea.Cmd {
+	return func() tea.Msg {
+		var meta entryMeta
+
+		if info, err := os.Lstat(path); err == nil {
+			meta.ModTime = info.ModTime()
+			if info.Mode()&os.ModeSymlink != 0 {
+				meta.LinkTarget, _ = os.Readlink(path)
+			}
+		}
+
+		if isDir {
+			meta.IsRepo, meta.Branch = gitBranch(path)
+			if meta.IsRepo {
+				meta.Dirty = gitDirty(path)
+			}
+		}
+		return entryMetaMsg{path: path, meta: meta}
+	}
+}
+
+// setEntryMeta stores loaded metadata on every listed copy of the entry.
+func (m *model) setEntryMeta(msg entryMetaMsg) {
+	delete(m.metaPending, msg.path)
+	for _, list := range [][]fileEntry{m.files, m.filteredFiles} {
+		for i := range list {
+			if list[i].Path == msg.path {
+				meta := msg.meta
+				list[i].Meta = &meta
+			}
+		}
+	}
+}
+
+// gitBranch reports whether dir is the root of a git repository and the
+// branch checked out there. HEAD is read directly to avoid running git.
+func gitBranch(dir string) (bool, string) {
+	gitPath := filepath.Join(dir, ".git")
+	info, err := os.Stat(gitPath)
+	if err != nil {
+		return false, ""
+	}
+
+	// Worktrees and submodules have a .git file pointing at the real one
+	gitDir := gitPath
+	if !info.IsDir() {
+		data, err := os.ReadFile(gitPath)
+		if err != nil {
+			return true, ""
+		}
+		target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+		if !ok {
+			return true, ""
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(dir, target)
+		}
+		gitDir = target
+	}
+
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return true, ""
+	}
+	ref := strings.TrimSpace(string(head))
+	if branch, ok := strings.CutPrefix(ref, "ref: refs/heads/"); ok {
+		return true, branch
+	}
+	if len(ref) > 7 {
+		ref = ref[:7]
+	}
+	return true, ref
+}
+
+// gitDirty reports whether tracked files in the repository have changes.
+func gitDirty(dir string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "status", "--porcelain", "--untracked-files=no").Output()
+	return err == nil && len(strings.TrimSpace(string(out))) > 0
+}
+
+// metaColumn renders an entry's metadata for the right-hand column.
+func metaColumn(meta *entryMeta, now time.Time) string {
+	if meta == nil {
+		return ""
+	}
+
+	var parts []string
+	if meta.IsRepo {
+		branch := "⎇ " + meta.Branch
+		if meta.Dirty {
+			branch += "*"
+		}
+		parts = append(parts, branch)
+	}
+	if meta.LinkTarget != "" {
+		target := meta.LinkTarget
+		if homeDir, err := os.UserHomeDir(); err == nil && strings.HasPrefix(target, homeDir) {
+			target = "~" + strings.TrimPrefix(target, homeDir)
+		}
+		if runes := []rune(target); len(runes) > maxLinkWidth {
+			target = "…" + string(runes[len(runes)-maxLinkWidth+1:])
+		}
+		parts = append(parts, "→ "+target)
+	}
+	if !meta.ModTime.IsZero() {
+		parts = append(parts, timeAgo(meta.ModTime, now))
+	}
+	return strings.Join(parts, "  ")
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxPathCompletions caps how many completion candidates are listed.
+const maxPathCompletions = 8
+
+// expandPath expands environment variables and a leading ~ in a path typed
+// by the user.
+func expandPath(path string) string {
+	return expandHome(os.ExpandEnv(path))
+}
+
+// resolveTypedPath turns a typed path or @bookmark into an absolute path.
+// Relative paths are relative to the directory being browsed.
+func (m model) resolveTypedPath(typed string) string {
+	path := strings.TrimSpace(typed)
+	if resolved, err := resolveBookmark(path); err == nil {
+		path = resolved
+	}
+	path = expandPath(path)
+	if path == "" {
+		return m.directory
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.directory, path)
+	}
+	return filepath.Clean(path)
+}
+
+// pathStatus describes the typed path for the inline flag under the input.
+// ok is true when the path is an existing directory.
+func (m model) pathStatus() (string, bool) {
+	if strings.TrimSpace(m.pathInput.Value()) == "" {
+		return "", false
+	}
+	info, err := os.Stat(m.resolveTypedPath(m.pathInput.Value()))
+	switch {
+	case os.IsNotExist(err):
+		return "✗ no such directory", false
+	case err != nil:
+		return "✗ " + err.Error(), false
+	case !info.IsDir():
+		return "✗ not a directory", false
+	}
+	return "✓ directory", true
+}
+
+// completePath completes the last path component like a shell: a single
+// candidate is filled in with a trailing slash, several are extended to
+// their common prefix and listed.
+func (m *model) completePath() {
+	typed := m.pathInput.Value()
+
+	// Keep what the user typed for the directory part (~, $VARS) and only
+	// replace the component being completed
+	typedDir, prefix := "", typed
+	if i := strings.LastIndex(typed, "/"); i >= 0 {
+		typedDir, prefix = typed[:i+1], typed[i+1:]
+	}
+	dir := m.directory
+	if typedDir != "" {
+		dir = m.resolveTypedPath(typedDir)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		m.pathCompletions = nil
+		return
+	}
+
+	var candidates []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		// Hidden directories only when asked for
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		if isDir(filepath.Join(dir, name), entry) {
+			candidates = append(candidates, name)
+		}
+	}
+	sort.Strings(candidates)
+
+	switch len(candidates) {
+	case 0:
+		m.pathCompletions = nil
+	case 1:
+		m.pathCompletions = nil
+		m.pathInput.SetValue(typedDir + candidates[0] + "/")
+	default:
+		m.pathCompletions = candidates
+		m.pathInput.SetValue(typedDir + commonPrefix(candidates))
+	}
+	m.pathInput.CursorEnd()
+}
+
+// isDir reports whether a directory entry is a directory, following
+// symlinks.
+func isDir(path string, entry os.DirEntry) bool {
+	if entry.IsDir() {
+		return true
+	}
+	if entry.Type()&os.ModeSymlink != 0 {
+		info, err := os.Stat(path)
+		return err == nil && info.IsDir()
+	}
+	return false
+}
+
+// commonPrefix returns the longest prefix of whole characters shared by
+// every word, so completing never leaves half of a multi-byte letter.
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// updatePathInput handles keys while typing a path in the directory chooser
+func (m model) updatePathInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.enteringPath = false
+		m.pathInput.Blur()
+		m.pathCompletions = nil
+		m.updateViewport() // Restore viewport to full size
+		return m, nil
+
+	case "tab":
+		m.completePath()
+		return m, nil
+
+	case "enter":
+		if _, ok := m.pathStatus(); !ok {
+			// The inline flag already says what is wrong
+			return m, nil
+		}
+		m.enteringPath = false
+		m.pathInput.Blur()
+		m.pathCompletions = nil
+		return m, m.loadDirectory(m.resolveTypedPath(m.pathInput.Value()))
+
+	default:
+		var cmd tea.Cmd
+		m.pathInput, cmd = m.pathInput.Update(msg)
+		m.pathCompletions = nil
+		return m, cmd
+	}
+}
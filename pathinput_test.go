@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		want  string
+	}{
+		{"one word", []string{"vinw"}, "vinw"},
+		{"shared start", []string{"vinw", "vinw-workspace", "vinw-viewer"}, "vinw"},
+		{"nothing shared", []string{"api", "web"}, ""},
+		{"multi-byte letter differs", []string{"café", "caff"}, "caf"},
+		{"multi-byte letters share a byte", []string{"café", "cafè"}, "caf"},
+		{"multi-byte letters shared", []string{"日本語", "日本人"}, "日本"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commonPrefix(tt.words); got != tt.want {
+				t.Errorf("commonPrefix(%q) = %q, want %q", tt.words, got, tt.want)
+			}
+		})
+	}
+}
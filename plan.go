@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// launchStep is one tmux command of a launch. Targets may name the pane
+// printed by an earlier step as $name, since pane IDs are only known once
+// tmux has created the pane.
+type launchStep struct {
+	Desc   string      // what the step does, for dry runs and errors
+	Cmd    tmuxCommand // for a wait, the capture-pane that reads the pane
+	Output string      // name the printed pane ID is saved under, "" for none
+	Wait   *ReadyCheck // set for steps that wait for a pane instead of running tmux
+}
+
+// launchPlan is the ordered list of tmux invocations that builds a
+// workspace, followed by the attach or switch that hands the terminal
+// over to it. Building the plan never touches the tmux server.
+type launchPlan struct {
+	Session string
+	Steps   []launchStep
+	Attach  []string // switch-client inside tmux, attach-session outside
+}
+
+// buildLaunchPlan turns a workspace into the tmux invocations that create
+// it: the session and its splits, session and pane markers, windows for
+// agents that do not fit the layout, extra windows, the pane and window
+// commands, and the pane that gets the focus.
+func buildLaunchPlan(spec workspaceSpec) (launchPlan, error) {
+	root, err := parseWindowgram(spec.Layout.Windowgram)
+	if err != nil {
+		return launchPlan{}, fmt.Errorf("invalid layout '%s': %w", spec.Layout.Name, err)
+	}
+	starts := paneStarts(root, spec)
+	placed, extraAgents := placeAgents(root, spec.Agents)
+	for key, check := range spec.Layout.Ready {
+		pane, size := utf8.DecodeRuneInString(key)
+		if _, ok := starts[pane]; !ok || size != len(key) {
+			return launchPlan{}, fmt.Errorf("layout '%s' has a ready check for pane %q, which it does not have", spec.Layout.Name, key)
+		}
+		if err := check.validate(); err != nil {
+			return launchPlan{}, fmt.Errorf("invalid ready check for pane %s: %w", key, err)
+		}
+	}
+	for _, window := range spec.Windows {
+		if window.Ready == nil {
+			continue
+		}
+		if err := window.Ready.validate(); err != nil {
+			return launchPlan{}, fmt.Errorf("invalid ready check for window %s: %w", window.Name, err)
+		}
+	}
+
+	plan := launchPlan{Session: spec.Session}
+	add := func(desc, output string, cmd tmuxCommand) {
+		plan.Steps = append(plan.Steps, launchStep{Desc: desc, Cmd: cmd, Output: output})
+	}
+	// A wait holds back every pane and window started after it
+	wait := func(desc, pane string, check ReadyCheck, dir string) {
+		check = check.inDir(dir)
+		plan.Steps = append(plan.Steps, launchStep{
+			Desc: fmt.Sprintf("wait for %s (%s)", desc, check),
+			Cmd:  capturePane{Target: "$" + pane},
+			Wait: &check,
+		})
+	}
+
+	// tmux starts every pane in its directory with -c, so paths never pass
+	// through a shell. The initial pane becomes the top-left pane of the
+	// layout; every split prints the new pane's ID so panes can be
+	// addressed by letter
+	first := root.firstPane()
+	add(fmt.Sprintf("create session '%s'", spec.Session), paneVar(first),
+		newSession{Session: spec.Session, Dir: starts[first].Dir})
+	for _, split := range root.splits() {
+		add(fmt.Sprintf("split pane %c for pane %c", split.Target, split.New), paneVar(split.New), splitWindow{
+			Target:     "$" + paneVar(split.Target),
+			Dir:        starts[split.New].Dir,
+			Horizontal: split.Horizontal,
+			Percent:    split.Percent,
+		})
+	}
+
+	// Splits share the window out by percentage; vinw's file tree keeps a
+	// fixed width on any screen
+	if root.besideOthers(paneVinw) {
+		add(fmt.Sprintf("resize pane %c", paneVinw), "", resizePane{Target: "$" + paneVar(paneVinw), Width: vinwWidth})
+	}
+
+	// Mark the session and pane roles so the session manager can find them
+	add("mark session", "", setOption{Target: spec.Session, Name: workspaceOption, Value: "1"})
+	if spec.Worktree != "" {
+		add("record worktree", "", setOption{Target: spec.Session, Name: worktreeOption, Value: spec.Worktree})
+	}
+	for _, pane := range root.panes() {
+		role := paneRole(pane)
+		if _, ok := placed[pane]; ok {
+			role = "agent"
+		}
+		if role != "" {
+			add(fmt.Sprintf("mark pane %c", pane), "", setOption{Target: "$" + paneVar(pane), Pane: true, Name: roleOption, Value: role})
+		}
+	}
+
+	// Every layout pane has a command to start. Agents without a pane in
+	// the layout get a window each; extra windows follow in the order they
+	// were declared
+	var commands []commandStart
+	for _, pane := range root.panes() {
+		desc := "start " + paneRole(pane)
+		switch {
+		case placed[pane].Name != "":
+			desc = "start agent " + placed[pane].Name
+		case pane == paneTerminal:
+			desc = "start terminal command"
+		}
+		command := commandStart{desc: desc, name: fmt.Sprintf("pane %c", pane), target: paneVar(pane), start: starts[pane]}
+		if check, ok := spec.Layout.Ready[string(pane)]; ok {
+			command.ready = &check
+		}
+		commands = append(commands, command)
+	}
+	for i, agent := range extraAgents {
+		start := agentStart(agent, spec.Dir)
+		id := fmt.Sprintf("agent_%d", i+1)
+		add("create window "+agent.Name, id, newWindow{Session: spec.Session, Name: agent.Name, Dir: start.Dir})
+		add("mark window "+agent.Name, "", setOption{Target: "$" + id, Pane: true, Name: roleOption, Value: "agent"})
+		commands = append(commands, commandStart{desc: "start agent " + agent.Name, name: "window " + agent.Name, target: id, start: start})
+	}
+	for i, window := range spec.Windows {
+		start := paneStart{Dir: window.workDir(spec.Dir), Command: window.Command}
+		id := fmt.Sprintf("window_%d", i+1)
+		add("create window "+window.Name, id, newWindow{Session: spec.Session, Name: window.Name, Dir: start.Dir})
+		commands = append(commands, commandStart{desc: "start window " + window.Name, name: "window " + window.Name, target: id, start: start, ready: window.Ready})
+	}
+
+	// Commands start once every pane and window exists. Those with a ready
+	// check go first, in layout and then window order, so a pane can wait
+	// for any other pane or window whatever its place in the layout
+	for _, ready := range []bool{true, false} {
+		for _, command := range commands {
+			if (command.ready != nil) != ready {
+				continue
+			}
+			for _, cmd := range startPaneCommands(spec.PaneStart, "$"+command.target, command.start) {
+				add(command.desc, "", cmd)
+			}
+			if command.ready != nil {
+				wait(command.name, command.target, *command.ready, command.start.Dir)
+			}
+		}
+	}
+
+	// Focus on vinw-viewer pane, or the first pane if the layout has none
+	focus := paneViewer
+	if _, ok := starts[focus]; !ok {
+		focus = first
+	}
+	add(fmt.Sprintf("focus pane %c", focus), "", selectPane{Target: "$" + paneVar(focus)})
+
+	plan.Attach = []string{"attach-session", "-t", spec.Session}
+	if isInTmux() {
+		plan.Attach = []string{"switch-client", "-t", spec.Session}
+	}
+	return plan, nil
+}
+
+// commandStart is a layout pane or window whose command a launch plan
+// starts.
+type commandStart struct {
+	desc   string // description of the start steps
+	name   string // "pane t" or "window logs", for the wait
+	target string // name the pane ID is saved under
+	start  paneStart
+	ready  *ReadyCheck
+}
+
+// paneVar is the name a pane's ID is saved under in a launch plan.
+func paneVar(pane rune) string {
+	return "pane_" + string(pane)
+}
+
+// waitPane returns the ID of the pane a wait step reads.
+func (s launchStep) waitPane(ids map[string]string) string {
+	return paneTarget(s.Cmd.(capturePane).Target, ids)
+}
+
+// scriptCommand renders a tmux invocation as a shell command. Targets
+// given as $name are expanded, every other argument is quoted.
+func scriptCommand(args []string) string {
+	words := []string{"tmux"}
+	for i, arg := range args {
+		if i > 0 && args[i-1] == "-t" && strings.HasPrefix(arg, "$") {
+			words = append(words, `"`+arg+`"`)
+			continue
+		}
+		words = append(words, shellQuote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// script renders a step as a shell command that keeps its output in a
+// variable, or a wait as a polling loop.
+func (s launchStep) script() string {
+	if s.Wait != nil {
+		return s.Wait.script(strings.TrimPrefix(s.Cmd.(capturePane).Target, "$"), s.Desc)
+	}
+	if s.Output != "" {
+		return s.Output + "=$(" + scriptCommand(s.Cmd.args()) + ")"
+	}
+	return scriptCommand(s.Cmd.args())
+}
+
+// dryRun lists the plan as numbered steps with the exact tmux commands,
+// after the git command that creates a planned worktree.
+func (p launchPlan) dryRun(worktree *worktreePlan) string {
+	var b strings.Builder
+	if worktree != nil && worktree.Add != nil {
+		fmt.Fprintf(&b, " 0. create worktree %s\n    %s\n", worktree.Path, worktree.addCommand())
+	}
+	for i, step := range p.Steps {
+		fmt.Fprintf(&b, "%2d. %s\n    %s\n", i+1, step.Desc, strings.ReplaceAll(step.script(), "\n", "\n    "))
+	}
+	fmt.Fprintf(&b, "%2d. hand the terminal over to '%s'\n    %s\n", len(p.Steps)+1, p.Session, scriptCommand(p.Attach))
+	return b.String()
+}
+
+// openPlan shows the dry run of the workspace the form would launch.
+func (m model) openPlan() (tea.Model, tea.Cmd) {
+	spec, worktree, err := m.formSpec()
+	var plan launchPlan
+	if err == nil {
+		plan, err = buildLaunchPlan(spec)
+	}
+	if err != nil {
+		m.statusMessage = statusMsg{text: err.Error(), isError: true}
+		return m, nil
+	}
+
+	// The container leaves m.height-4 lines; title and footer take 2 each.
+	// Long commands wrap rather than run off the edge
+	m.planViewport = viewport.New(m.width-8, m.height-8)
+	m.planViewport.SetContent(lipgloss.NewStyle().Width(m.width - 8).Render(plan.dryRun(worktree)))
+	m.showingPlan = true
+	m.statusMessage = statusMsg{}
+	return m, nil
+}
+
+// updatePlan scrolls the launch plan
+func (m model) updatePlan(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc", "d":
+		m.showingPlan = false
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.planViewport, cmd = m.planViewport.Update(msg)
+	return m, cmd
+}
+
+// viewPlan renders the launch plan over the preview
+func (m model) viewPlan() string {
+	var s strings.Builder
+	s.WriteString(sectionTitleStyle.Render("Launch Plan") + "  " + blurredStyle.Render(m.inputs[0].Value()) + "\n\n")
+	s.WriteString(m.planViewport.View() + "\n\n")
+	s.WriteString(helpStyle.Render("↑/↓ j/k: scroll • esc/d: back • q: quit"))
+
+	container := lipgloss.NewStyle().
+		Width(m.width-2).
+		Height(m.height-2).
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(purpleColor)
+
+	return container.Render(s.String())
+}
@@ -0,0 +1,406 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxRecent is how many recent launches are remembered.
+const maxRecent = 10
+
+// Preset is a saved workspace form. Recent launches use the same shape
+// without a name.
+type Preset struct {
+	Name     string   `json:"name,omitempty"`
+	Dir      string   `json:"dir"`
+	Session  string   `json:"session"`
+	Terminal string   `json:"terminal"`
+	Agent    string   `json:"agent"`             // one name, or several separated by commas
+	Command  string   `json:"command,omitempty"` // command name, or a shell command
+	Layout   string   `json:"layout,omitempty"`
+	Worktree string   `json:"worktree,omitempty"` // branch to check out in a git worktree
+	Windows  []Window `json:"windows,omitempty"`
+}
+
+// PresetsConfig stores presets and recent launches in presets.json
+type PresetsConfig struct {
+	Presets []Preset `json:"presets"`
+	Recent  []Preset `json:"recent"`
+}
+
+func presetsFile() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "presets.json"), nil
+}
+
+// loadPresets loads presets from ~/.vinw-workspace/presets.json
+func loadPresets() (PresetsConfig, error) {
+	var presets PresetsConfig
+
+	file, err := presetsFile()
+	if err != nil {
+		return presets, err
+	}
+
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return presets, nil
+	}
+	if err != nil {
+		return presets, err
+	}
+
+	if err := json.Unmarshal(data, &presets); err != nil {
+		return PresetsConfig{}, fmt.Errorf("invalid presets.json: %w", err)
+	}
+	return presets, nil
+}
+
+func savePresets(presets PresetsConfig) error {
+	file, err := presetsFile()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(presets, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, data, 0644)
+}
+
+// savePreset adds a preset, replacing any preset with the same name.
+func savePreset(preset Preset) error {
+	presets, err := loadPresets()
+	if err != nil {
+		return err
+	}
+	presets.Presets = append(removePreset(presets.Presets, preset.Name), preset)
+	return savePresets(presets)
+}
+
+// recordRecent puts a launch at the top of the recent list. Launching the
+// same directory and session again moves it up instead of adding a copy.
+func recordRecent(launch Preset) error {
+	presets, err := loadPresets()
+	if err != nil {
+		return err
+	}
+
+	launch.Name = ""
+	recent := []Preset{launch}
+	for _, p := range presets.Recent {
+		if p.Dir != launch.Dir || p.Session != launch.Session {
+			recent = append(recent, p)
+		}
+	}
+	if len(recent) > maxRecent {
+		recent = recent[:maxRecent]
+	}
+	presets.Recent = recent
+	return savePresets(presets)
+}
+
+func findPreset(presets []Preset, name string) (Preset, bool) {
+	for _, p := range presets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+func removePreset(presets []Preset, name string) []Preset {
+	var out []Preset
+	for _, p := range presets {
+		if p.Name != name {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// formPreset captures the current form as a preset
+func (m model) formPreset(name string) Preset {
+	preset := Preset{
+		Name:     name,
+		Dir:      m.directory,
+		Session:  m.inputs[0].Value(),
+		Terminal: m.terminalOptions[m.terminalCursor],
+		Agent:    m.agentValue(),
+		Layout:   m.layouts[m.layoutCursor].Name,
+		Worktree: strings.TrimSpace(m.inputs[1].Value()),
+		Windows:  m.windows,
+	}
+	if m.selectedCommandIdx >= 0 && m.selectedCommandIdx < len(m.workspaceCommands) {
+		preset.Command = m.workspaceCommands[m.selectedCommandIdx].Name
+	}
+	return preset
+}
+
+// applyPreset fills the form from a preset. Options that no longer exist
+// keep the form's current selection. The returned command lists the
+// preset's directory in the chooser.
+func (m *model) applyPreset(preset Preset) (tea.Cmd, error) {
+	if info, err := os.Stat(preset.Dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("directory %s no longer exists", preset.Dir)
+	}
+
+	cmd := m.loadDirectory(preset.Dir)
+	if preset.Session != "" {
+		m.inputs[0].SetValue(preset.Session)
+	}
+	m.inputs[1].SetValue(preset.Worktree)
+	m.worktreeBranch = preset.Worktree
+	for i, opt := range m.terminalOptions {
+		if opt == preset.Terminal {
+			m.terminalCursor = i
+		}
+	}
+	m.selectAgents(splitAgentNames(preset.Agent))
+	for i, l := range m.layouts {
+		if l.Name == preset.Layout {
+			m.layoutCursor = i
+		}
+	}
+	m.selectCommand(preset.Command, "From preset")
+	m.setPresetWindows(preset.Windows)
+	m.setWorkspaceCommands(m.workspaceCommands)
+	return cmd, nil
+}
+
+// presetEntries lists named presets first, then recent launches
+func (m model) presetEntries() []Preset {
+	return append(append([]Preset{}, m.presets.Presets...), m.presets.Recent...)
+}
+
+// openPresets switches to the presets screen with a fresh list
+func openPresets(m model) model {
+	m.currentState = statePresets
+	presets, err := loadPresets()
+	m.presets = presets
+	m.statusMessage = statusMsg{}
+	if err != nil {
+		m.statusMessage = statusMsg{text: err.Error(), isError: true}
+	}
+	if entries := m.presetEntries(); m.presetsCursor >= len(entries) {
+		m.presetsCursor = max(0, len(entries)-1)
+	}
+	return m
+}
+
+// updatePresets handles the recent & presets view
+func updatePresets(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	entries := m.presetEntries()
+
+	// Deleting needs confirmation
+	if m.confirmDeletePreset {
+		m.confirmDeletePreset = false
+		if keyMsg.String() != "y" {
+			return m, nil
+		}
+		if m.presetsCursor < len(m.presets.Presets) {
+			m.presets.Presets = removePreset(m.presets.Presets, entries[m.presetsCursor].Name)
+		} else {
+			i := m.presetsCursor - len(m.presets.Presets)
+			m.presets.Recent = append(m.presets.Recent[:i], m.presets.Recent[i+1:]...)
+		}
+		if err := savePresets(m.presets); err != nil {
+			m.statusMessage = statusMsg{text: err.Error(), isError: true}
+			return m, nil
+		}
+		return openPresets(m), nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+
+	case "esc":
+		m.currentState = stateMenu
+		m.statusMessage = statusMsg{}
+		return m, nil
+
+	case "up", "k":
+		if m.presetsCursor > 0 {
+			m.presetsCursor--
+		}
+
+	case "down", "j":
+		if m.presetsCursor < len(entries)-1 {
+			m.presetsCursor++
+		}
+
+	case "i":
+		return openImport(m)
+	}
+
+	if len(entries) == 0 {
+		return m, nil
+	}
+	selected := entries[m.presetsCursor]
+
+	switch keyMsg.String() {
+	case "enter":
+		cmd, err := m.applyPreset(selected)
+		if err != nil {
+			m.statusMessage = statusMsg{text: err.Error(), isError: true}
+			return m, nil
+		}
+		m.statusMessage = statusMsg{}
+
+		// Launch straight away; anything that needs a decision (a running
+		// session, missing dependencies) is shown on the preview screen
+		deps := checkDependencies(m.terminalOptions[m.terminalCursor], m.selectedAgents())
+		_, layoutErr := parseWindowgram(m.layouts[m.layoutCursor].Windowgram)
+		if sessionExists(m.inputs[0].Value()) || !allDependenciesAvailable(deps) || layoutErr != nil {
+			m.currentState = statePreview
+			return m, cmd
+		}
+		// The directory keeps loading for when the user comes back
+		next, launchCmd := m.startLaunch(conflictFail)
+		return next, tea.Batch(cmd, launchCmd)
+
+	case "e":
+		// Open in the form to change something first
+		cmd, err := m.applyPreset(selected)
+		if err != nil {
+			m.statusMessage = statusMsg{text: err.Error(), isError: true}
+			return m, nil
+		}
+		m.statusMessage = statusMsg{}
+		m.currentState = stateForm
+		m.focusIndex = focusSession
+		return m, tea.Batch(cmd, m.focusInputs())
+
+	case "x", "d":
+		m.confirmDeletePreset = true
+	}
+
+	return m, nil
+}
+
+// viewPresets renders the recent & presets view
+func viewPresets(m model) string {
+	var s strings.Builder
+
+	listTitleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(pinkColor).
+		Padding(0, 0, 1, 0)
+
+	s.WriteString(listTitleStyle.Render("⭐ Recent & Presets"))
+	s.WriteString("\n\n")
+
+	entries := m.presetEntries()
+	if len(entries) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(grayColor).
+			Align(lipgloss.Center).
+			Width(60)
+		s.WriteString(emptyStyle.Render("No presets yet - press 's' on the preview screen to save one"))
+		s.WriteString("\n\n")
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	nameStyle := lipgloss.NewStyle().Foreground(lightGray)
+	selectedNameStyle := lipgloss.NewStyle().Foreground(greenColor).Bold(true)
+
+	for i, p := range entries {
+		if i == 0 && len(m.presets.Presets) > 0 {
+			s.WriteString(sectionTitleStyle.Render("Presets") + "\n")
+		}
+		if i == len(m.presets.Presets) {
+			if i > 0 {
+				s.WriteString("\n")
+			}
+			s.WriteString(sectionTitleStyle.Render("Recent") + "\n")
+		}
+
+		displayDir := p.Dir
+		if strings.HasPrefix(displayDir, homeDir) {
+			displayDir = "~" + strings.TrimPrefix(displayDir, homeDir)
+		}
+
+		title := p.Name
+		if title == "" {
+			title = p.Session + " " + blurredStyle.Render(displayDir)
+		}
+		line := "  " + nameStyle.Render(title)
+		if i == m.presetsCursor {
+			line = selectedNameStyle.Render("▸ ") + selectedNameStyle.Render(title)
+		}
+		s.WriteString(line + "\n")
+
+		var details []string
+		if p.Name != "" {
+			details = append(details, displayDir, p.Session)
+		}
+		// Imported presets leave the terminal and agent to the defaults
+		for _, detail := range []string{p.Terminal, p.Agent} {
+			if detail != "" {
+				details = append(details, detail)
+			}
+		}
+		if p.Command != "" {
+			details = append(details, p.Command)
+		}
+		if p.Layout != "" {
+			details = append(details, p.Layout)
+		}
+		if p.Worktree != "" {
+			details = append(details, "⎇ "+p.Worktree)
+		}
+		if len(p.Windows) > 0 {
+			details = append(details, "+ "+strings.Join(windowNames(p.Windows), ", "))
+		}
+		s.WriteString(blurredStyle.Render("    "+strings.Join(details, " • ")) + "\n")
+	}
+
+	s.WriteString("\n")
+
+	if m.confirmDeletePreset {
+		s.WriteString(errorStyle.Render("⚠ Remove this entry?"))
+		s.WriteString("\n\n")
+		s.WriteString(helpStyle.Render("y: remove • any other key: cancel"))
+	} else {
+		if m.statusMessage.text != "" {
+			statusStyle := successStyle.Bold(true)
+			if m.statusMessage.isError {
+				statusStyle = errorStyle.Bold(true)
+			}
+			s.WriteString(statusStyle.Render(m.statusMessage.text) + "\n\n")
+		}
+
+		helpText := "enter: launch • e: edit first • x: remove • i: import • esc: back"
+		if len(entries) == 0 {
+			helpText = "i: import tmuxinator/tmuxp • esc: back"
+		}
+		s.WriteString(helpStyle.Render(helpText))
+	}
+
+	// Full-height container
+	fullHeightContainer := lipgloss.NewStyle().
+		Width(m.width-2).
+		Height(m.height-2).
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(purpleColor)
+
+	return fullHeightContainer.Render(s.String())
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPresetLaunchKeepsLoadingDirectory(t *testing.T) {
+	fake := cliEnv(t)
+	installFakes(t, "vinw", "vinw-viewer", "tmux")
+	dir := t.TempDir()
+	if err := savePreset(Preset{Name: "api", Dir: dir, Session: "api", Terminal: "shell", Agent: "none"}); err != nil {
+		t.Fatal(err)
+	}
+	fake.Fail = map[string]string{"split-window": "no space for new pane"}
+
+	m := openPresets(initialModel())
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = runLaunching(next.(model), cmd)
+	if m.currentState != stateLaunching || m.launchRun.err == nil {
+		t.Fatalf("state = %v, err = %v; want the launch stopped at the split", m.currentState, m.launchRun.err)
+	}
+
+	m, _ = pressKey(m, "esc")
+	if m.dirLoading || m.directory != dir {
+		t.Errorf("loading = %v, directory = %q after the failed launch; want %s listed", m.dirLoading, m.directory, dir)
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// projectFileName is the per-project workspace file looked up in the
+// directory being launched.
+const projectFileName = ".vinw-workspace.json"
+
+// ProjectConfig is a workspace definition checked into a repository.
+//
+// Precedence when launching: command-line flags, then the project file,
+// then the global config in ~/.vinw-workspace. Project terminals, agents,
+// commands and layouts are added to the global lists; entries with the
+// same name replace the global ones. Windows open alongside the layout.
+type ProjectConfig struct {
+	Session  string             `json:"session,omitempty"`
+	Terminal string             `json:"terminal,omitempty"`
+	Agent    string             `json:"agent,omitempty"`   // agent names from Agents or the global agent_options, comma separated
+	Command  string             `json:"command,omitempty"` // command name from Commands, or a shell command
+	Layout   string             `json:"layout,omitempty"`
+	Commands []WorkspaceCommand `json:"commands,omitempty"`
+	Layouts  []Layout           `json:"layouts,omitempty"`
+	Agents   []Agent            `json:"agents,omitempty"`
+	Windows  []Window           `json:"windows,omitempty"`
+}
+
+// loadProjectConfig reads the project file in dir. The bool is false when
+// the directory has no project file.
+func loadProjectConfig(dir string) (ProjectConfig, bool, error) {
+	var project ProjectConfig
+
+	data, err := os.ReadFile(filepath.Join(dir, projectFileName))
+	if os.IsNotExist(err) {
+		return project, false, nil
+	}
+	if err != nil {
+		return project, false, err
+	}
+
+	if err := json.Unmarshal(data, &project); err != nil {
+		return project, false, fmt.Errorf("invalid %s: %w", projectFileName, err)
+	}
+	return project, true, nil
+}
+
+// saveProjectConfig writes a project file into dir.
+func saveProjectConfig(dir string, project ProjectConfig) error {
+	data, err := json.MarshalIndent(project, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, projectFileName), append(data, '\n'), 0644)
+}
+
+// mergeProjectConfig layers a project file over the global config.
+func mergeProjectConfig(config Config, project ProjectConfig) Config {
+	merged := Config{
+		TerminalOptions: append([]string{}, config.TerminalOptions...),
+		AgentOptions:    append([]Agent{}, config.AgentOptions...),
+		Layouts:         append([]Layout{}, config.Layouts...),
+		PaneStart:       config.PaneStart,
+		ProjectRoots:    config.ProjectRoots,
+		ScanDepth:       config.ScanDepth,
+		ScanSkip:        config.ScanSkip,
+		WorktreeDir:     config.WorktreeDir,
+	}
+
+	if project.Terminal != "" && !contains(merged.TerminalOptions, project.Terminal) {
+		merged.TerminalOptions = append(merged.TerminalOptions, project.Terminal)
+	}
+	for _, agent := range project.Agents {
+		replaced := false
+		for i := range merged.AgentOptions {
+			if merged.AgentOptions[i].Name == agent.Name {
+				merged.AgentOptions[i] = agent
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged.AgentOptions = append(merged.AgentOptions, agent)
+		}
+	}
+	for _, name := range splitAgentNames(project.Agent) {
+		if !contains(agentNames(merged.AgentOptions), name) {
+			merged.AgentOptions = append(merged.AgentOptions, Agent{Name: name})
+		}
+	}
+
+	for _, layout := range project.Layouts {
+		replaced := false
+		for i := range merged.Layouts {
+			if merged.Layouts[i].Name == layout.Name {
+				merged.Layouts[i] = layout
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged.Layouts = append(merged.Layouts, layout)
+		}
+	}
+
+	return merged
+}
+
+// mergeProjectCommands adds project commands to the global list, marking
+// them so they are never written back to workspace.conf.
+func mergeProjectCommands(commands []WorkspaceCommand, project ProjectConfig) []WorkspaceCommand {
+	var merged []WorkspaceCommand
+	for _, cmd := range commands {
+		if cmd.Project {
+			continue
+		}
+		overridden := false
+		for _, projectCmd := range project.Commands {
+			if projectCmd.Name == cmd.Name {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, cmd)
+		}
+	}
+
+	for _, cmd := range project.Commands {
+		cmd.Project = true
+		merged = append(merged, cmd)
+	}
+	return merged
+}
+
+// resolveCommand returns the shell command for a command name, or the value
+// itself when it does not name a known command.
+func resolveCommand(commands []WorkspaceCommand, value string) string {
+	for _, cmd := range commands {
+		if cmd.Name == value {
+			return cmd.Command
+		}
+	}
+	return value
+}
+
+// applyProject refreshes the form options for a directory, prefilling the
+// form from its project file when there is one.
+func (m *model) applyProject(dir string) {
+	m.projectDir = dir
+	project, found, err := loadProjectConfig(dir)
+	m.err = err
+	if !found {
+		project = ProjectConfig{}
+		m.projectFile = ""
+	} else {
+		m.projectFile = filepath.Join(dir, projectFileName)
+	}
+
+	merged := mergeProjectConfig(m.config, project)
+
+	// Keep the current selections when they are still available
+	m.terminalCursor = indexOr(merged.TerminalOptions, project.Terminal, m.terminalOptions, m.terminalCursor)
+	projectAgents := splitAgentNames(project.Agent)
+	m.agentCursor = indexOr(agentNames(merged.AgentOptions), firstOr(projectAgents, ""), agentNames(m.agentOptions), m.agentCursor)
+
+	layoutNames := make([]string, len(merged.Layouts))
+	for i, l := range merged.Layouts {
+		layoutNames[i] = l.Name
+	}
+	currentLayouts := make([]string, len(m.layouts))
+	for i, l := range m.layouts {
+		currentLayouts[i] = l.Name
+	}
+	m.layoutCursor = indexOr(layoutNames, project.Layout, currentLayouts, m.layoutCursor)
+
+	m.terminalOptions = merged.TerminalOptions
+	m.agentOptions = merged.AgentOptions
+	m.layouts = merged.Layouts
+	if len(projectAgents) > 1 {
+		m.selectAgents(projectAgents)
+	}
+
+	// Swap project commands, keeping the selection by name
+	selectedName := ""
+	if m.selectedCommandIdx >= 0 && m.selectedCommandIdx < len(m.workspaceCommands) {
+		selectedName = m.workspaceCommands[m.selectedCommandIdx].Name
+	}
+	m.setProjectWindows(project)
+	m.setWorkspaceCommands(mergeProjectCommands(m.workspaceCommands, project))
+	if project.Command != "" {
+		m.selectCommand(project.Command, "From "+projectFileName)
+	} else {
+		m.selectedCommandIdx = -1
+		for i, cmd := range m.workspaceCommands {
+			if cmd.Name == selectedName {
+				m.selectedCommandIdx = i
+				break
+			}
+		}
+	}
+
+	if project.Session != "" {
+		m.inputs[0].SetValue(project.Session)
+	}
+}
+
+// selectCommand selects the command with the given name. A value that names
+// no command is a plain shell command and is added as a one-off entry that
+// is never saved to workspace.conf.
+func (m *model) selectCommand(value, description string) {
+	m.selectedCommandIdx = -1
+	if value == "" {
+		return
+	}
+	for i, cmd := range m.workspaceCommands {
+		if cmd.Name == value {
+			m.selectedCommandIdx = i
+			return
+		}
+	}
+	m.setWorkspaceCommands(append(m.workspaceCommands, WorkspaceCommand{
+		Name:        value,
+		Command:     value,
+		Description: description,
+		Project:     true,
+	}))
+	m.selectedCommandIdx = len(m.workspaceCommands) - 1
+}
+
+// indexOr picks the index of want in options, falling back to the option
+// that was selected before the list changed, then to the first option.
+func indexOr(options []string, want string, previous []string, previousIdx int) int {
+	if want != "" {
+		for i, opt := range options {
+			if opt == want {
+				return i
+			}
+		}
+	}
+	if previousIdx >= 0 && previousIdx < len(previous) {
+		for i, opt := range options {
+			if opt == previous[previousIdx] {
+				return i
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestProjectFileAppliedOnDirectoryChange(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir, other := t.TempDir(), t.TempDir()
+	if err := saveProjectConfig(dir, ProjectConfig{Session: "proj", Layout: "three-column"}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := initialModel()
+	m.loadDirectory(dir)
+	if got := m.inputs[0].Value(); got != "proj" || m.layouts[m.layoutCursor].Name != "three-column" {
+		t.Fatalf("session = %q, layout = %q; want the project file's", got, m.layouts[m.layoutCursor].Name)
+	}
+
+	// Going back to the chooser reloads the same directory
+	m.inputs[0].SetValue("mine")
+	m.layoutCursor = 0
+	m.loadDirectory(dir)
+	if got := m.inputs[0].Value(); got != "mine" || m.layoutCursor != 0 {
+		t.Errorf("reload reset the form: session = %q, layout = %q", got, m.layouts[m.layoutCursor].Name)
+	}
+
+	m.loadDirectory(other)
+	m.loadDirectory(dir)
+	if got := m.inputs[0].Value(); got != "proj" || m.layouts[m.layoutCursor].Name != "three-column" {
+		t.Errorf("returning to the project: session = %q, layout = %q; want the project file's", got, m.layouts[m.layoutCursor].Name)
+	}
+}
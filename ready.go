@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultReadyTimeout is how long a readiness check may take when it sets
+// no timeout of its own.
+const defaultReadyTimeout = 30 * time.Second
+
+// readyPollInterval is how often a readiness check is tried.
+const readyPollInterval = 250 * time.Millisecond
+
+// ReadyCheck is what a pane waits for after its command starts, before the
+// panes after it are started. Every condition that is set must hold.
+type ReadyCheck struct {
+	Port    int    `json:"port,omitempty"`    // TCP port on localhost accepting connections
+	File    string `json:"file,omitempty"`    // path that exists, relative to the pane's directory unless absolute
+	Output  string `json:"output,omitempty"`  // regular expression matched against the pane's visible output
+	Delay   string `json:"delay,omitempty"`   // fixed wait, e.g. "2s"
+	Timeout string `json:"timeout,omitempty"` // give up after this long, default 30s
+
+	pattern *regexp.Regexp // Output compiled by inDir
+}
+
+// validate checks the durations and the output pattern.
+func (c ReadyCheck) validate() error {
+	if c.Port == 0 && c.File == "" && c.Output == "" && c.Delay == "" {
+		return fmt.Errorf("nothing to wait for: set port, file, output or delay")
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid port %d", c.Port)
+	}
+	if c.Output != "" {
+		if _, err := regexp.Compile(c.Output); err != nil {
+			return fmt.Errorf("invalid output pattern: %w", err)
+		}
+		if syntax := nonERESyntax(c.Output); syntax != "" {
+			return fmt.Errorf("invalid output pattern: %s is not POSIX extended syntax, which the dry-run script's grep -E needs", syntax)
+		}
+	}
+	for _, d := range []string{c.Delay, c.Timeout} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(d); err != nil {
+			return fmt.Errorf("invalid duration %q", d)
+		}
+	}
+	return nil
+}
+
+func (c ReadyCheck) delay() time.Duration {
+	d, _ := time.ParseDuration(c.Delay)
+	return d
+}
+
+func (c ReadyCheck) timeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultReadyTimeout
+}
+
+// String describes the conditions for dry runs and progress.
+func (c ReadyCheck) String() string {
+	var parts []string
+	if c.Port != 0 {
+		parts = append(parts, fmt.Sprintf("port %d", c.Port))
+	}
+	if c.File != "" {
+		parts = append(parts, "file "+c.File)
+	}
+	if c.Output != "" {
+		parts = append(parts, "output /"+c.Output+"/")
+	}
+	if c.Delay != "" {
+		parts = append(parts, c.Delay+" delay")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// nonERESyntax returns the first part of a Go pattern that grep -E reads
+// differently: escapes such as \d or \b, (?...) groups, lazy quantifiers
+// and backslashes inside brackets. It returns "" for a pattern both accept.
+func nonERESyntax(pattern string) string {
+	inBracket := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		var next byte
+		if i+1 < len(pattern) {
+			next = pattern[i+1]
+		}
+		switch {
+		case c == '\\':
+			if inBracket || next >= '0' && next <= '9' || next >= 'a' && next <= 'z' || next >= 'A' && next <= 'Z' {
+				return pattern[i : i+2]
+			}
+			i++
+		case inBracket:
+			if c == '[' && next == ':' {
+				if end := strings.Index(pattern[i:], ":]"); end > 0 {
+					i += end + 1
+				}
+			} else if c == ']' {
+				inBracket = false
+			}
+		case c == '[':
+			inBracket = true
+			// A ] right after [ or [^ is a literal
+			if next == '^' {
+				i++
+			}
+			if i+1 < len(pattern) && pattern[i+1] == ']' {
+				i++
+			}
+		case c == '(' && next == '?':
+			return "(?"
+		case strings.IndexByte("*+?}", c) >= 0 && next == '?':
+			return pattern[i : i+2]
+		}
+	}
+	return ""
+}
+
+// inDir resolves a relative file against the pane's directory and compiles
+// the output pattern, which validate has checked.
+func (c ReadyCheck) inDir(dir string) ReadyCheck {
+	if c.File != "" {
+		file := expandHome(c.File)
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		c.File = file
+	}
+	if c.Output != "" {
+		c.pattern, _ = regexp.Compile(c.Output)
+	}
+	return c
+}
+
+// probe tries the check once, waited after the pane was started.
+func (c ReadyCheck) probe(client tmuxClient, pane string, waited time.Duration) (bool, error) {
+	if waited < c.delay() {
+		return false, nil
+	}
+	if c.Port != 0 {
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort("localhost", strconv.Itoa(c.Port)), readyPollInterval)
+		if err != nil {
+			return false, nil
+		}
+		conn.Close()
+	}
+	if c.File != "" {
+		if _, err := os.Stat(c.File); err != nil {
+			return false, nil
+		}
+	}
+	if c.Output != "" {
+		if c.pattern == nil {
+			return false, fmt.Errorf("invalid output pattern %q", c.Output)
+		}
+		out, err := client.CapturePane(capturePane{Target: pane})
+		if err != nil {
+			return false, err
+		}
+		if !c.pattern.MatchString(out) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// waitReady polls a check until it holds or times out.
+func waitReady(client tmuxClient, check ReadyCheck, pane string) error {
+	start := time.Now()
+	for {
+		waited := time.Since(start)
+		ready, err := check.probe(client, pane, waited)
+		if err != nil {
+			return err
+		}
+		if ready {
+			return nil
+		}
+		if waited > check.timeout() {
+			return fmt.Errorf("timed out after %s", check.timeout())
+		}
+		time.Sleep(readyPollInterval)
+	}
+}
+
+// script renders the check as a shell loop that gives up after the
+// timeout. pane is the variable holding the pane's ID.
+func (c ReadyCheck) script(pane, desc string) string {
+	var conds []string
+	if c.Port != 0 {
+		conds = append(conds, fmt.Sprintf("nc -z localhost %d", c.Port))
+	}
+	if c.File != "" {
+		conds = append(conds, "[ -e "+shellQuote(c.File)+" ]")
+	}
+	if c.Output != "" {
+		conds = append(conds, fmt.Sprintf(`tmux capture-pane -p -t "$%s" | grep -Eq %s`, pane, shellQuote(c.Output)))
+	}
+
+	var lines []string
+	if c.Delay != "" {
+		lines = append(lines, fmt.Sprintf("sleep %g", c.delay().Seconds()))
+	}
+	if len(conds) > 0 {
+		tries := int(math.Ceil(c.timeout().Seconds()))
+		lines = append(lines, fmt.Sprintf(`i=0; until %s; do i=$((i+1)); [ "$i" -lt %d ] || { echo %s >&2; exit 1; }; sleep 1; done`,
+			strings.Join(conds, " && "), tries, shellQuote("timed out: "+desc)))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// openSessions switches to the session manager with a fresh session list
+func openSessions(m model) model {
+	m.currentState = stateSessions
+	m.sessions = getTmuxSessions()
+	if m.sessionsCursor >= len(m.sessions) {
+		m.sessionsCursor = max(0, len(m.sessions)-1)
+	}
+	m.statusMessage = statusMsg{}
+	return m
+}
+
+// updateSessions handles the session manager view
+func updateSessions(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	// Rename input takes all keys while open
+	if m.renamingSession {
+		switch keyMsg.String() {
+		case "esc":
+			m.renamingSession = false
+			m.sessionRenameInput.Blur()
+			return m, nil
+		case "enter":
+			name := strings.TrimSpace(m.sessionRenameInput.Value())
+			current := m.sessions[m.sessionsCursor].Name
+			m.renamingSession = false
+			m.sessionRenameInput.Blur()
+			if name == "" || name == current {
+				return m, nil
+			}
+			if err := renameSession(current, name); err != nil {
+				m = openSessions(m)
+				m.statusMessage = statusMsg{text: err.Error(), isError: true}
+				return m, nil
+			}
+			m = openSessions(m)
+			m.statusMessage = statusMsg{text: fmt.Sprintf("✓ Renamed '%s' to '%s'", current, name)}
+			return m, nil
+		default:
+			var cmd tea.Cmd
+			m.sessionRenameInput, cmd = m.sessionRenameInput.Update(keyMsg)
+			return m, cmd
+		}
+	}
+
+	// Killing a session needs confirmation; w also removes its worktree
+	if m.confirmKillSession {
+		m.confirmKillSession = false
+		selected := m.sessions[m.sessionsCursor]
+		removeTree := keyMsg.String() == "w" && selected.Worktree != ""
+		if keyMsg.String() != "y" && !removeTree {
+			return m, nil
+		}
+		if err := killSession(selected.Name); err != nil {
+			m = openSessions(m)
+			m.statusMessage = statusMsg{text: err.Error(), isError: true}
+			return m, nil
+		}
+		status := fmt.Sprintf("✓ Killed '%s'", selected.Name)
+		if removeTree {
+			// The warning about uncommitted changes was shown with the prompt
+			if err := removeWorktree(selected.Worktree, true); err != nil {
+				m = openSessions(m)
+				m.statusMessage = statusMsg{text: err.Error(), isError: true}
+				return m, nil
+			}
+			status += " and removed its worktree"
+		}
+		m = openSessions(m)
+		m.statusMessage = statusMsg{text: status}
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+
+	case "esc":
+		m.currentState = stateMenu
+		m.statusMessage = statusMsg{}
+		return m, nil
+
+	case "up", "k":
+		if m.sessionsCursor > 0 {
+			m.sessionsCursor--
+		}
+
+	case "down", "j":
+		if m.sessionsCursor < len(m.sessions)-1 {
+			m.sessionsCursor++
+		}
+
+	case "g":
+		// Refresh the list
+		m = openSessions(m)
+	}
+
+	if len(m.sessions) == 0 {
+		return m, nil
+	}
+	selected := m.sessions[m.sessionsCursor]
+
+	switch keyMsg.String() {
+	case "enter":
+		// Attach after the TUI exits so tmux can take over the terminal
+		m.attachTarget = selected.Name
+		return m, tea.Quit
+
+	case "v":
+		// Jump straight to the vinw pane
+		paneID, err := findRolePane(selected.Name, "vinw")
+		if err == nil {
+			err = focusPane(paneID)
+		}
+		if err != nil {
+			m.statusMessage = statusMsg{text: err.Error(), isError: true}
+			return m, nil
+		}
+		m.attachTarget = selected.Name
+		return m, tea.Quit
+
+	case "r":
+		m.renamingSession = true
+		m.sessionRenameInput.SetValue(selected.Name)
+		m.sessionRenameInput.CursorEnd()
+		return m, m.sessionRenameInput.Focus()
+
+	case "x", "d":
+		m.confirmKillSession = true
+		if selected.Worktree != "" {
+			changes, err := worktreeChanges(selected.Worktree)
+			if err != nil {
+				changes = -1
+			}
+			m.worktreeChanges = changes
+		}
+	}
+
+	return m, nil
+}
+
+// viewSessions renders the session manager
+func viewSessions(m model) string {
+	var s strings.Builder
+
+	listTitleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(pinkColor).
+		Padding(0, 0, 1, 0)
+
+	s.WriteString(listTitleStyle.Render("🗂  Sessions"))
+	s.WriteString("\n\n")
+
+	if len(m.sessions) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(grayColor).
+			Align(lipgloss.Center).
+			Width(60)
+		s.WriteString(emptyStyle.Render("No tmux sessions running"))
+		s.WriteString("\n\n")
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	nameStyle := lipgloss.NewStyle().Foreground(lightGray)
+	selectedNameStyle := lipgloss.NewStyle().Foreground(greenColor).Bold(true)
+	tagStyle := lipgloss.NewStyle().Foreground(purpleColor)
+
+	for i, sess := range m.sessions {
+		displayDir := sess.Path
+		if strings.HasPrefix(displayDir, homeDir) {
+			displayDir = "~" + strings.TrimPrefix(displayDir, homeDir)
+		}
+
+		name := "  " + sess.Name
+		style := nameStyle
+		if i == m.sessionsCursor {
+			name = "▸ " + sess.Name
+			style = selectedNameStyle
+		}
+
+		var tags []string
+		if sess.Attached {
+			tags = append(tags, "attached")
+		}
+		if sess.Workspace {
+			tags = append(tags, "vinw-workspace")
+		}
+		if sess.Worktree != "" {
+			tags = append(tags, "worktree")
+		}
+		line := style.Render(name)
+		if len(tags) > 0 {
+			line += " " + tagStyle.Render("["+strings.Join(tags, ", ")+"]")
+		}
+		s.WriteString(line + "\n")
+
+		details := fmt.Sprintf("    %s • %d windows • %d panes", displayDir, sess.Windows, sess.Panes)
+		s.WriteString(blurredStyle.Render(details) + "\n")
+		if i < len(m.sessions)-1 {
+			s.WriteString("\n")
+		}
+	}
+
+	s.WriteString("\n")
+
+	if m.renamingSession {
+		s.WriteString(sectionTitleStyle.Render("Rename Session") + "\n")
+		s.WriteString(m.sessionRenameInput.View() + "\n\n")
+		s.WriteString(helpStyle.Render("enter: rename • esc: cancel"))
+	} else if m.confirmKillSession {
+		selected := m.sessions[m.sessionsCursor]
+		s.WriteString(errorStyle.Render(fmt.Sprintf("⚠ Kill session '%s' and everything running in it?", selected.Name)))
+		s.WriteString("\n\n")
+		if selected.Worktree == "" {
+			s.WriteString(helpStyle.Render("y: kill • any other key: cancel"))
+		} else {
+			worktree := selected.Worktree
+			if strings.HasPrefix(worktree, homeDir) {
+				worktree = "~" + strings.TrimPrefix(worktree, homeDir)
+			}
+			s.WriteString(blurredStyle.Render("  Worktree: "+worktree) + "\n")
+			switch {
+			case m.worktreeChanges < 0:
+				s.WriteString(errorStyle.Render("  ⚠ Could not check the worktree for uncommitted changes") + "\n")
+			case m.worktreeChanges > 0:
+				s.WriteString(errorStyle.Render(fmt.Sprintf("  ⚠ %d uncommitted changes will be lost if the worktree is removed", m.worktreeChanges)) + "\n")
+			}
+			s.WriteString("\n")
+			s.WriteString(helpStyle.Render("y: kill and keep worktree • w: kill and remove worktree • any other key: cancel"))
+		}
+	} else {
+		if m.statusMessage.text != "" {
+			statusStyle := successStyle.Bold(true)
+			if m.statusMessage.isError {
+				statusStyle = errorStyle.Bold(true)
+			}
+			s.WriteString(statusStyle.Render(m.statusMessage.text) + "\n\n")
+		}
+
+		helpText := "enter: attach • v: jump to vinw • r: rename • x: kill • g: refresh • esc: back"
+		if len(m.sessions) == 0 {
+			helpText = "g: refresh • esc: back"
+		}
+		s.WriteString(helpStyle.Render(helpText))
+	}
+
+	// Full-height container
+	fullHeightContainer := lipgloss.NewStyle().
+		Width(m.width-2).
+		Height(m.height-2).
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(purpleColor)
+
+	return fullHeightContainer.Render(s.String())
+}
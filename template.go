@@ -13,7 +13,27 @@ func generateSessionID(path string) string {
 	return fmt.Sprintf("%x", hash[:4])
 }
 
-func getPreviewContent(dir, session, terminal, agent, customCmd string) string {
+// paneLabels returns the short names shown for each pane role in layout
+// diagrams. placed holds the agent of each agent pane, see placeAgents.
+func paneLabels(terminal string, placed map[rune]Agent, customCmd string) map[rune]string {
+	labels := map[rune]string{
+		paneVinw:     "vinw",
+		paneViewer:   "vinw-viewer",
+		paneTerminal: "term",
+		paneAgent:    "agent",
+	}
+	if customCmd != "" {
+		labels[paneTerminal] = "custom"
+	} else if terminal == "nextui" {
+		labels[paneTerminal] = "nextui"
+	}
+	for pane, agent := range placed {
+		labels[pane] = agent.Name
+	}
+	return labels
+}
+
+func getPreviewContent(dir, session, terminal string, agents []Agent, customCmd string, layout Layout) string {
 	absDir, _ := filepath.Abs(dir)
 	sessionID := generateSessionID(absDir)
 
@@ -26,36 +46,50 @@ func getPreviewContent(dir, session, terminal, agent, customCmd string) string {
 		terminalDisplay = "shell (empty terminal)"
 	}
 
-	if agent == "none" {
+	if len(agents) == 0 {
 		agentDisplay = "none (empty terminal)"
 	} else {
-		agentDisplay = agent
+		agentDisplay = strings.Join(agentNames(agents), ", ")
 	}
 
-	windowgram := `aaaavvvvvvvvvvvvvvvvvvvvvvvvv
-aaaavvvvvvvvvvvvvvvvvvvvvvvvv
-aaaavvvvvvvvvvvvvvvvvvvvvvvvv
-aaaavvvvvvvvvvvvvvvvvvvvvvvvv
-aaaattttttttttttttccccccccccc
-aaaattttttttttttttccccccccccc
-aaaattttttttttttttccccccccccc`
-
 	var sb strings.Builder
 	sb.WriteString("Layout Preview\n\n")
-	sb.WriteString(windowgram)
+	sb.WriteString(strings.TrimSpace(layout.Windowgram))
 	sb.WriteString("\n\n")
 	sb.WriteString("Configuration\n")
 	sb.WriteString(fmt.Sprintf("  Directory:  %s\n", absDir))
 	sb.WriteString(fmt.Sprintf("  Session:    %s\n", session))
 	sb.WriteString(fmt.Sprintf("  Session ID: %s\n", sessionID))
+	sb.WriteString(fmt.Sprintf("  Layout:     %s\n", layout.Name))
 	sb.WriteString(fmt.Sprintf("  Terminal:   %s\n", terminalDisplay))
-	sb.WriteString(fmt.Sprintf("  Agent:      %s\n", agentDisplay))
+	sb.WriteString(fmt.Sprintf("  Agents:     %s\n", agentDisplay))
 	sb.WriteString("\n")
 	sb.WriteString("Pane Layout\n")
-	sb.WriteString("  [a] vinw file browser\n")
-	sb.WriteString(fmt.Sprintf("  [v] vinw-viewer %s\n", sessionID))
-	sb.WriteString(fmt.Sprintf("  [t] %s\n", terminalDisplay))
-	sb.WriteString(fmt.Sprintf("  [c] %s\n", agentDisplay))
+
+	root, err := parseWindowgram(layout.Windowgram)
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("  invalid layout: %v\n", err))
+		return sb.String()
+	}
+	placed, extra := placeAgents(root, agents)
+	for _, pane := range root.panes() {
+		command := paneCommand(pane, terminal, sessionID, customCmd)
+		agent, isAgent := placed[pane]
+		switch {
+		case isAgent:
+			command = agent.commandLine()
+		case pane == paneVinw:
+			command = "vinw file browser"
+		case pane == paneTerminal:
+			command = terminalDisplay
+		case command == "":
+			command = "shell (empty terminal)"
+		}
+		sb.WriteString(fmt.Sprintf("  [%c] %s\n", pane, command))
+	}
+	for _, agent := range extra {
+		sb.WriteString(fmt.Sprintf("  window %s: %s\n", agent.Name, agent.commandLine()))
+	}
 
 	return sb.String()
 }
@@ -1,188 +1,710 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"os/exec"
+	"strconv"
+	"strings"
 
 	"github.com/GianlucaP106/gotmux/gotmux"
 )
 
-func isInTmux() bool {
-	return os.Getenv("TMUX") != ""
+// tmuxClient is the tmux server vinw-workspace drives, with a method for
+// each tmux command it uses. Launch plans hold the commands as values (see
+// tmuxCommand) so dry runs can print them; queries return the requested
+// format variables of each line.
+type tmuxClient interface {
+	HasSession(session string) bool
+	NewSession(c newSession) (string, error)   // ID of the session's first pane
+	SplitWindow(c splitWindow) (string, error) // ID of the new pane
+	NewWindow(c newWindow) (string, error)     // ID of the window's pane
+	ResizePane(c resizePane) error
+	SendKeys(c sendKeys) error
+	RespawnPane(c respawnPane) error
+	SetOption(c setOption) error
+	SelectPane(c selectPane) error
+	SelectWindow(target string) error
+	CapturePane(c capturePane) (string, error) // the pane's visible output
+	RenameSession(session, name string) error
+	KillSession(session string) error
+	ListSessions(vars ...string) ([]tmuxVars, error)
+	ListPanes(session string, vars ...string) ([]tmuxVars, error) // every session's panes when session is ""
+	SessionVars(session string, vars ...string) (tmuxVars, error)
+	Attach(session string) error
+	SwitchClient(session string) error
 }
 
-func sessionExists(session string) bool {
+// tmuxServer is the client used outside of tests.
+var tmuxServer tmuxClient = gotmuxClient{}
+
+// gotmuxClient drives tmux through gotmux where gotmux has the command:
+// checking for, attaching to and switching to a session. gotmux cannot
+// pass the flags the launch commands need (-P -F, -p, -l, respawn-pane),
+// does not read user options in formats and drops tmux's error message,
+// so every other command runs tmux through run.
+type gotmuxClient struct{}
+
+func (gotmuxClient) tmux() (*gotmux.Tmux, error) {
 	tmux, err := gotmux.DefaultTmux()
 	if err != nil {
-		return false
+		return nil, fmt.Errorf("failed to initialize tmux: %w", err)
 	}
-	return tmux.HasSession(session)
+	return tmux, nil
 }
 
-func getTmuxSessions() []string {
-	tmux, err := gotmux.DefaultTmux()
+func (c gotmuxClient) HasSession(session string) bool {
+	tmux, err := c.tmux()
 	if err != nil {
-		return []string{}
+		return false
 	}
-	sessions, err := tmux.ListSessions()
+	return tmux.HasSession(session)
+}
+
+// run starts tmux with args and returns its stdout. Errors carry tmux's
+// own message, such as "can't find pane: %7".
+func (gotmuxClient) run(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("tmux", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
 	if err != nil {
-		return []string{}
-	}
-	names := make([]string, len(sessions))
-	for i, s := range sessions {
-		names[i] = s.Name
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("tmux %s: %s", args[0], msg)
 	}
-	return names
+	return string(out), nil
 }
 
-func launchTmuxSession(dir, session, terminal, agent, sessionID, customCmd string) error {
-	absDir := os.ExpandEnv(dir)
+func (c gotmuxClient) NewSession(cmd newSession) (string, error) {
+	out, err := c.run(cmd.args()...)
+	return strings.TrimSpace(out), err
+}
 
-	tmux, err := gotmux.DefaultTmux()
+func (c gotmuxClient) SplitWindow(cmd splitWindow) (string, error) {
+	out, err := c.run(cmd.args()...)
+	return strings.TrimSpace(out), err
+}
+
+func (c gotmuxClient) ResizePane(cmd resizePane) error {
+	_, err := c.run(cmd.args()...)
+	return err
+}
+
+func (c gotmuxClient) NewWindow(cmd newWindow) (string, error) {
+	out, err := c.run(cmd.args()...)
+	return strings.TrimSpace(out), err
+}
+
+func (c gotmuxClient) SendKeys(cmd sendKeys) error {
+	_, err := c.run(cmd.args()...)
+	return err
+}
+
+func (c gotmuxClient) RespawnPane(cmd respawnPane) error {
+	_, err := c.run(cmd.args()...)
+	return err
+}
+
+func (c gotmuxClient) SetOption(cmd setOption) error {
+	_, err := c.run(cmd.args()...)
+	return err
+}
+
+func (c gotmuxClient) SelectPane(cmd selectPane) error {
+	_, err := c.run(cmd.args()...)
+	return err
+}
+
+func (c gotmuxClient) SelectWindow(target string) error {
+	_, err := c.run("select-window", "-t", target)
+	return err
+}
+
+func (c gotmuxClient) CapturePane(cmd capturePane) (string, error) {
+	return c.run(cmd.args()...)
+}
+
+func (c gotmuxClient) RenameSession(session, name string) error {
+	_, err := c.run("rename-session", "-t", session+":", name)
+	return err
+}
+
+func (c gotmuxClient) KillSession(session string) error {
+	_, err := c.run("kill-session", "-t", session+":")
+	return err
+}
+
+func (c gotmuxClient) ListSessions(vars ...string) ([]tmuxVars, error) {
+	out, err := c.run("list-sessions", "-F", varsFormat(vars))
 	if err != nil {
-		return fmt.Errorf("failed to initialize tmux: %w", err)
+		return nil, err
 	}
+	return parseVars(out, vars), nil
+}
 
-	if tmux.HasSession(session) {
-		return fmt.Errorf("session '%s' already exists - choose a different name", session)
+func (c gotmuxClient) ListPanes(session string, vars ...string) ([]tmuxVars, error) {
+	args := []string{"list-panes", "-a", "-F", varsFormat(vars)}
+	if session != "" {
+		args = []string{"list-panes", "-s", "-t", session + ":", "-F", varsFormat(vars)}
 	}
-
-	// Create detached session with starting directory
-	sess, err := tmux.NewSession(&gotmux.SessionOptions{
-		Name:           session,
-		StartDirectory: absDir,
-	})
+	out, err := c.run(args...)
 	if err != nil {
-		return fmt.Errorf("failed to create session: %w", err)
+		return nil, err
 	}
+	return parseVars(out, vars), nil
+}
 
-	// Get the auto-created window and pane
-	windows, err := sess.ListWindows()
+func (c gotmuxClient) SessionVars(session string, vars ...string) (tmuxVars, error) {
+	out, err := c.run("display-message", "-p", "-t", session+":", varsFormat(vars))
 	if err != nil {
-		return fmt.Errorf("failed to list windows: %w", err)
+		return nil, err
 	}
-	if len(windows) == 0 {
-		return fmt.Errorf("no windows found in session")
+	rows := parseVars(out, vars)
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("tmux display-message: no output for '%s'", session)
 	}
-	window := windows[0]
+	return rows[0], nil
+}
 
-	panes, err := window.ListPanes()
+func (c gotmuxClient) Attach(session string) error {
+	tmux, err := c.tmux()
+	if err != nil {
+		return err
+	}
+	sess, err := tmux.Session(session)
 	if err != nil {
-		return fmt.Errorf("failed to list panes: %w", err)
+		return fmt.Errorf("failed to find session '%s': %w", session, err)
 	}
-	if len(panes) == 0 {
-		return fmt.Errorf("no panes found in window")
+	if sess == nil {
+		return fmt.Errorf("can't find session: %s", session)
 	}
-	pane0 := panes[0]
+	return sess.Attach()
+}
 
-	// Pane 0 (left): vinw - start the command
-	_, err = tmux.Command("send-keys", "-R", "-t", pane0.Id, fmt.Sprintf("cd %s && vinw", absDir), "Enter")
+func (c gotmuxClient) SwitchClient(session string) error {
+	tmux, err := c.tmux()
 	if err != nil {
-		return fmt.Errorf("failed to start vinw: %w", err)
+		return err
 	}
+	return tmux.SwitchClient(&gotmux.SwitchClientOptions{TargetSession: session})
+}
 
-	// Split horizontally - right pane will be larger
-	_, err = tmux.Command("split-window", "-h", "-c", absDir, "-t", pane0.Id)
-	if err != nil {
-		return fmt.Errorf("failed to create right split: %w", err)
+// tmuxCommand is a tmux command a launch plan runs. Pane targets may name
+// a pane ID saved by an earlier step as $name, filled in by run.
+type tmuxCommand interface {
+	args() []string                                               // the tmux command line, for dry runs and scripts
+	run(client tmuxClient, ids map[string]string) (string, error) // the pane ID printed, if any
+}
+
+// newSession creates a detached session whose first pane starts in Dir.
+type newSession struct {
+	Session string
+	Dir     string
+}
+
+func (c newSession) args() []string {
+	return []string{"new-session", "-d", "-s", c.Session, "-c", c.Dir, "-P", "-F", "#{pane_id}"}
+}
+
+func (c newSession) run(client tmuxClient, ids map[string]string) (string, error) {
+	return client.NewSession(c)
+}
+
+// splitWindow splits Target to create a pane starting in Dir.
+type splitWindow struct {
+	Target     string
+	Dir        string
+	Horizontal bool // new pane goes to the right (-h) instead of below (-v)
+	Percent    int  // size of the new pane relative to the target
+}
+
+func (c splitWindow) args() []string {
+	direction := "-v"
+	if c.Horizontal {
+		direction = "-h"
 	}
+	return []string{
+		"split-window", direction,
+		"-p", strconv.Itoa(c.Percent),
+		"-c", c.Dir,
+		"-t", c.Target,
+		"-P", "-F", "#{pane_id}",
+	}
+}
 
-	// Resize left pane (vinw) to 43 columns
-	_, err = tmux.Command("resize-pane", "-t", pane0.Id, "-x", "43")
-	if err != nil {
-		return fmt.Errorf("failed to resize left pane: %w", err)
+func (c splitWindow) run(client tmuxClient, ids map[string]string) (string, error) {
+	c.Target = paneTarget(c.Target, ids)
+	return client.SplitWindow(c)
+}
+
+// resizePane sets the width of a pane in columns.
+type resizePane struct {
+	Target string
+	Width  int
+}
+
+func (c resizePane) args() []string {
+	return []string{"resize-pane", "-t", c.Target, "-x", strconv.Itoa(c.Width)}
+}
+
+func (c resizePane) run(client tmuxClient, ids map[string]string) (string, error) {
+	c.Target = paneTarget(c.Target, ids)
+	return "", client.ResizePane(c)
+}
+
+// newWindow adds a background window to a session, starting in Dir.
+type newWindow struct {
+	Session string
+	Name    string
+	Dir     string
+}
+
+func (c newWindow) args() []string {
+	return []string{
+		"new-window", "-d",
+		"-t", c.Session + ":",
+		"-n", c.Name,
+		"-c", c.Dir,
+		"-P", "-F", "#{pane_id}",
 	}
+}
 
-	// Refresh panes list and get pane 1
-	panes, err = window.ListPanes()
-	if err != nil {
-		return fmt.Errorf("failed to list panes after first split: %w", err)
+func (c newWindow) run(client tmuxClient, ids map[string]string) (string, error) {
+	return client.NewWindow(c)
+}
+
+// sendKeys types into a pane. Literal text is sent with -l, after
+// resetting the pane's terminal state, so tmux never reads words in it as
+// key names; otherwise Keys is a key name such as Enter.
+type sendKeys struct {
+	Target  string
+	Keys    string
+	Literal bool
+}
+
+func (c sendKeys) args() []string {
+	if c.Literal {
+		return []string{"send-keys", "-R", "-t", c.Target, "-l", c.Keys}
 	}
-	if len(panes) < 2 {
-		return fmt.Errorf("expected 2 panes after first split")
+	return []string{"send-keys", "-t", c.Target, c.Keys}
+}
+
+func (c sendKeys) run(client tmuxClient, ids map[string]string) (string, error) {
+	c.Target = paneTarget(c.Target, ids)
+	return "", client.SendKeys(c)
+}
+
+// respawnPane replaces the process in a pane with Command, started in Dir.
+type respawnPane struct {
+	Target  string
+	Dir     string
+	Command []string
+}
+
+func (c respawnPane) args() []string {
+	return append([]string{"respawn-pane", "-k", "-t", c.Target, "-c", c.Dir}, c.Command...)
+}
+
+func (c respawnPane) run(client tmuxClient, ids map[string]string) (string, error) {
+	c.Target = paneTarget(c.Target, ids)
+	return "", client.RespawnPane(c)
+}
+
+// setOption sets an option of a session, or of a pane when Pane is set.
+type setOption struct {
+	Target string // session name, or pane
+	Pane   bool
+	Name   string
+	Value  string
+}
+
+func (c setOption) args() []string {
+	if c.Pane {
+		return []string{"set-option", "-p", "-t", c.Target, c.Name, c.Value}
 	}
-	pane1 := panes[1]
+	return []string{"set-option", "-t", c.Target + ":", c.Name, c.Value}
+}
 
-	// Pane 1 (top-right): vinw-viewer - 48% of right side height
-	_, err = tmux.Command("send-keys", "-R", "-t", pane1.Id, fmt.Sprintf("cd %s && vinw-viewer %s", absDir, sessionID), "Enter")
-	if err != nil {
-		return fmt.Errorf("failed to start vinw-viewer: %w", err)
+func (c setOption) run(client tmuxClient, ids map[string]string) (string, error) {
+	if c.Pane {
+		c.Target = paneTarget(c.Target, ids)
 	}
+	return "", client.SetOption(c)
+}
 
-	// Split vertically from pane 1 - bottom gets 52% height
-	_, err = tmux.Command("split-window", "-v", "-p", "52", "-c", absDir, "-t", pane1.Id)
-	if err != nil {
-		return fmt.Errorf("failed to create bottom-right split: %w", err)
+// selectPane makes a pane the active pane of its window.
+type selectPane struct {
+	Target string
+}
+
+func (c selectPane) args() []string {
+	return []string{"select-pane", "-t", c.Target}
+}
+
+func (c selectPane) run(client tmuxClient, ids map[string]string) (string, error) {
+	c.Target = paneTarget(c.Target, ids)
+	return "", client.SelectPane(c)
+}
+
+// capturePane reads the visible output of a pane.
+type capturePane struct {
+	Target string
+}
+
+func (c capturePane) args() []string {
+	return []string{"capture-pane", "-p", "-t", c.Target}
+}
+
+func (c capturePane) run(client tmuxClient, ids map[string]string) (string, error) {
+	c.Target = paneTarget(c.Target, ids)
+	return client.CapturePane(c)
+}
+
+// paneTarget fills in a pane ID saved by an earlier step for a $name
+// target.
+func paneTarget(target string, ids map[string]string) string {
+	if id, ok := ids[strings.TrimPrefix(target, "$")]; ok && strings.HasPrefix(target, "$") {
+		return id
 	}
+	return target
+}
+
+func isInTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+func sessionExists(session string) bool {
+	return tmuxServer.HasSession(session)
+}
+
+// Options set on sessions and panes created by vinw-workspace
+const (
+	workspaceOption = "@vinw-workspace" // session: set to 1
+	roleOption      = "@vinw-role"      // pane: vinw, vinw-viewer, terminal, agent
+	worktreeOption  = "@vinw-worktree"  // session: git worktree it was launched in
+)
+
+// fieldSep separates fields in tmux -F formats. tmux 3.3 prints tabs and
+// other control characters as "_", so a printable separator is used, the
+// same one gotmux uses.
+const fieldSep = "-:-"
+
+// tmuxVars is one line of a tmux list or display-message: the value of
+// each requested format variable, such as session_name or @vinw-role.
+type tmuxVars map[string]string
+
+// varsFormat is the -F format that prints vars separated by fieldSep.
+func varsFormat(vars []string) string {
+	formats := make([]string, len(vars))
+	for i, name := range vars {
+		formats[i] = "#{" + name + "}"
+	}
+	return strings.Join(formats, fieldSep)
+}
+
+// parseVars reads the lines tmux printed for varsFormat(vars). A separator
+// inside the last variable stays in it, so a path goes last.
+func parseVars(out string, vars []string) []tmuxVars {
+	if out == "" {
+		return nil
+	}
+	var rows []tmuxVars
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		fields := strings.SplitN(line, fieldSep, len(vars))
+		if len(fields) < len(vars) {
+			continue
+		}
+		row := tmuxVars{}
+		for i, name := range vars {
+			row[name] = fields[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
 
-	// Refresh panes list and get pane 2
-	panes, err = window.ListPanes()
+// sessionSummary is one row of the session manager.
+type sessionSummary struct {
+	Name      string
+	Path      string
+	Windows   int
+	Panes     int
+	Attached  bool
+	Workspace bool   // created by vinw-workspace
+	Worktree  string // git worktree the session was launched in
+}
+
+func getTmuxSessions() []sessionSummary {
+	// The path comes last so a separator inside it stays in that field.
+	// Session names cannot contain ':', so they never hold the separator
+	rows, err := tmuxServer.ListSessions("session_name", "session_windows", "session_attached", workspaceOption, "session_path")
 	if err != nil {
-		return fmt.Errorf("failed to list panes after second split: %w", err)
+		return []sessionSummary{}
 	}
-	if len(panes) < 3 {
-		return fmt.Errorf("expected 3 panes after second split")
+
+	// Pane counts come from a single list over every session, and so do
+	// worktrees, which are paths too
+	paneCounts := map[string]int{}
+	if panes, err := tmuxServer.ListPanes("", "session_name"); err == nil {
+		for _, pane := range panes {
+			paneCounts[pane["session_name"]]++
+		}
+	}
+	worktrees := map[string]string{}
+	if trees, err := tmuxServer.ListSessions("session_name", worktreeOption); err == nil {
+		for _, tree := range trees {
+			worktrees[tree["session_name"]] = tree[worktreeOption]
+		}
 	}
-	pane2 := panes[2]
 
-	// Pane 2 (bottom-right top): custom command, or terminal/nextui if requested
-	if customCmd != "" {
-		// Run custom command
-		_, err = tmux.Command("send-keys", "-R", "-t", pane2.Id, fmt.Sprintf("cd %s && %s", absDir, customCmd), "Enter")
-		if err != nil {
-			return fmt.Errorf("failed to start custom command: %w", err)
+	var sessions []sessionSummary
+	for _, row := range rows {
+		name := row["session_name"]
+		windows, _ := strconv.Atoi(row["session_windows"])
+		attached, _ := strconv.Atoi(row["session_attached"])
+		sessions = append(sessions, sessionSummary{
+			Name:      name,
+			Path:      row["session_path"],
+			Windows:   windows,
+			Panes:     paneCounts[name],
+			Attached:  attached > 0,
+			Workspace: row[workspaceOption] == "1",
+			Worktree:  worktrees[name],
+		})
+	}
+	return sessions
+}
+
+func renameSession(session, name string) error {
+	if tmuxServer.HasSession(name) {
+		return fmt.Errorf("session '%s' already exists", name)
+	}
+	if err := tmuxServer.RenameSession(session, name); err != nil {
+		return fmt.Errorf("failed to rename session '%s': %w", session, err)
+	}
+	return nil
+}
+
+// findRolePane returns the ID of the pane with the given role in a session.
+// Sessions created before roles were recorded fall back to the pane's
+// running command.
+func findRolePane(session, role string) (string, error) {
+	panes, err := tmuxServer.ListPanes(session, "pane_id", roleOption, "pane_current_command")
+	if err != nil {
+		return "", fmt.Errorf("failed to list panes of '%s': %w", session, err)
+	}
+
+	fallback := ""
+	for _, pane := range panes {
+		if pane[roleOption] == role {
+			return pane["pane_id"], nil
 		}
-	} else if terminal == "nextui" {
-		_, err = tmux.Command("send-keys", "-R", "-t", pane2.Id, fmt.Sprintf("cd %s && nextui", absDir), "Enter")
-		if err != nil {
-			return fmt.Errorf("failed to start nextui: %w", err)
+		if fallback == "" && pane["pane_current_command"] == role {
+			fallback = pane["pane_id"]
 		}
 	}
+	if fallback == "" {
+		return "", fmt.Errorf("no %s pane in session '%s'", role, session)
+	}
+	return fallback, nil
+}
+
+// focusPane makes a pane the active pane of its session so attaching lands
+// on it.
+func focusPane(paneID string) error {
+	if err := tmuxServer.SelectWindow(paneID); err != nil {
+		return fmt.Errorf("failed to select window: %w", err)
+	}
+	if err := tmuxServer.SelectPane(selectPane{Target: paneID}); err != nil {
+		return fmt.Errorf("failed to select pane: %w", err)
+	}
+	return nil
+}
 
-	// Split horizontally from pane 2 - 50/50 split
-	_, err = tmux.Command("split-window", "-h", "-c", absDir, "-t", pane2.Id)
+// How to handle a session name that is already taken
+const (
+	conflictFail     = "fail"     // refuse to launch
+	conflictAttach   = "attach"   // attach or switch to the existing session
+	conflictSuffix   = "suffix"   // create the session as name-2, name-3, ...
+	conflictRecreate = "recreate" // kill the existing session and launch again
+)
+
+var conflictPolicies = []string{conflictFail, conflictAttach, conflictSuffix, conflictRecreate}
+
+// sessionInfo describes a live session for the conflict prompt.
+type sessionInfo struct {
+	Name         string
+	Path         string
+	PaneCommands []string
+}
+
+// describeSession returns the start directory and running pane commands of
+// a live session.
+func describeSession(session string) (sessionInfo, error) {
+	info := sessionInfo{Name: session}
+
+	vars, err := tmuxServer.SessionVars(session, "session_path")
 	if err != nil {
-		return fmt.Errorf("failed to create agent pane: %w", err)
+		return info, fmt.Errorf("failed to read session '%s': %w", session, err)
 	}
+	info.Path = vars["session_path"]
 
-	// Refresh panes list and get pane 3
-	panes, err = window.ListPanes()
+	panes, err := tmuxServer.ListPanes(session, "pane_current_command")
 	if err != nil {
-		return fmt.Errorf("failed to list panes after third split: %w", err)
+		return info, fmt.Errorf("failed to list panes of '%s': %w", session, err)
 	}
-	if len(panes) < 4 {
-		return fmt.Errorf("expected 4 panes after third split")
+	for _, pane := range panes {
+		if command := pane["pane_current_command"]; command != "" {
+			info.PaneCommands = append(info.PaneCommands, command)
+		}
 	}
-	pane3 := panes[3]
 
-	// Pane 3 (bottom-right bottom): agent if requested
-	if agent != "none" && agent != "" {
-		_, err = tmux.Command("send-keys", "-R", "-t", pane3.Id, fmt.Sprintf("cd %s && %s", absDir, agent), "Enter")
-		if err != nil {
-			return fmt.Errorf("failed to start agent: %w", err)
+	return info, nil
+}
+
+// nextSessionName returns the first free name of the form base-2, base-3, ...
+func nextSessionName(base string) string {
+	for i := 2; ; i++ {
+		name := fmt.Sprintf("%s-%d", base, i)
+		if !sessionExists(name) {
+			return name
 		}
 	}
+}
 
-	// Focus on vinw-viewer pane
-	_, err = tmux.Command("select-pane", "-t", pane1.Id)
+// sessionWorktree returns the git worktree a session was launched in, or
+// "" for sessions launched in a plain directory.
+func sessionWorktree(session string) (string, error) {
+	vars, err := tmuxServer.SessionVars(session, worktreeOption)
 	if err != nil {
-		return fmt.Errorf("failed to select pane: %w", err)
+		return "", fmt.Errorf("failed to read session '%s': %w", session, err)
+	}
+	return vars[worktreeOption], nil
+}
+
+func killSession(session string) error {
+	if err := tmuxServer.KillSession(session); err != nil {
+		return fmt.Errorf("failed to kill session '%s': %w", session, err)
 	}
+	return nil
+}
+
+// attachSession switches the current client to the session when running
+// inside tmux, and attaches this terminal to it otherwise.
+func attachSession(session string) error {
+	return attachClient(tmuxServer, session)
+}
 
-	// Attach or switch to session
+func attachClient(client tmuxClient, session string) error {
 	if isInTmux() {
-		err = tmux.SwitchClient(&gotmux.SwitchClientOptions{
-			TargetSession: session,
-		})
-		if err != nil {
+		if err := client.SwitchClient(session); err != nil {
 			return fmt.Errorf("failed to switch client: %w", err)
 		}
-	} else {
-		err = sess.Attach()
-		if err != nil {
-			return fmt.Errorf("failed to attach session: %w", err)
+		return nil
+	}
+	if err := client.Attach(session); err != nil {
+		return fmt.Errorf("failed to attach session: %w", err)
+	}
+	return nil
+}
+
+// resolveSessionConflict applies a conflict policy to the requested session
+// name. It returns the name to use and whether that existing session should
+// be attached instead of launching a new one.
+func resolveSessionConflict(session, policy string) (string, bool, error) {
+	if !sessionExists(session) {
+		return session, false, nil
+	}
+
+	switch policy {
+	case conflictAttach:
+		return session, true, nil
+	case conflictSuffix:
+		return nextSessionName(session), false, nil
+	case conflictRecreate:
+		if err := killSession(session); err != nil {
+			return "", false, err
 		}
+		return session, false, nil
+	default:
+		return "", false, fmt.Errorf("session '%s' already exists - choose a different name", session)
 	}
+}
+
+// paneRole names a pane letter for the @vinw-role pane option.
+func paneRole(pane rune) string {
+	switch pane {
+	case paneVinw:
+		return "vinw"
+	case paneViewer:
+		return "vinw-viewer"
+	case paneTerminal:
+		return "terminal"
+	case paneAgent:
+		return "agent"
+	}
+	return ""
+}
+
+// workspaceSpec is everything needed to launch one workspace.
+type workspaceSpec struct {
+	Dir       string
+	Session   string
+	Terminal  string
+	Agents    []Agent // in agent panes c, d, e, ... then in windows of their own
+	SessionID string
+	CustomCmd string
+	Layout    Layout
+	PaneStart string   // how pane commands are started, see startPaneCommands
+	Worktree  string   // git worktree Dir is in, recorded on the session
+	Windows   []Window // extra windows opened after the layout
+}
 
+// executeLaunchPlan runs a launch plan against a tmux server. When a step
+// fails after the session was created, the half-built session is killed so
+// it does not block the next launch. Attaching is left to the caller.
+func executeLaunchPlan(client tmuxClient, plan launchPlan) error {
+	if client.HasSession(plan.Session) {
+		return fmt.Errorf("session '%s' already exists - choose a different name", plan.Session)
+	}
+
+	ids := map[string]string{}
+	for i, step := range plan.Steps {
+		out, err := runLaunchStep(client, step, ids)
+		if err != nil {
+			err = fmt.Errorf("failed to %s: %w", step.Desc, err)
+			// Nothing exists yet if creating the session failed, and a
+			// session of that name may belong to someone else
+			if i > 0 {
+				err = rollbackSession(client, plan.Session, err)
+			}
+			return err
+		}
+		if step.Output != "" {
+			ids[step.Output] = out
+		}
+	}
 	return nil
 }
+
+// runLaunchStep runs one step of a launch plan, waiting out a readiness
+// check, and returns the pane ID the step printed.
+func runLaunchStep(client tmuxClient, step launchStep, ids map[string]string) (string, error) {
+	if step.Wait != nil {
+		return "", waitReady(client, *step.Wait, step.waitPane(ids))
+	}
+	return step.Cmd.run(client, ids)
+}
+
+// rollbackSession kills a session whose launch failed with err and says
+// so in the returned error.
+func rollbackSession(client tmuxClient, session string, err error) error {
+	if killErr := client.KillSession(session); killErr != nil {
+		return fmt.Errorf("%w; the partly created session '%s' could not be removed: %v", err, session, killErr)
+	}
+	return fmt.Errorf("%w; removed the partly created session '%s'", err, session)
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// fakeTmux is an in-memory tmux server. It acts on the typed commands
+// rather than parsing tmux arguments, records every call as the arguments
+// tmux would get, hands out pane IDs %0, %1, ... in creation order and
+// keeps enough state (windows, panes, options, typed keys) to check what a
+// launch built.
+type fakeTmux struct {
+	Calls    [][]string
+	Sessions []*fakeSession
+	Attached string            // session attached or switched to
+	Fail     map[string]string // tmux command -> error message, to simulate failures
+
+	nextPane int
+}
+
+type fakeSession struct {
+	Name    string
+	Windows []*fakeWindow
+	Options map[string]string
+}
+
+type fakeWindow struct {
+	Name  string
+	Panes []*fakePane
+}
+
+type fakePane struct {
+	ID      string
+	Dir     string
+	Split   string // how the pane was split off, e.g. "-h 79% of %0"
+	Width   int    // columns set with resize-pane
+	Options map[string]string
+	Keys    string // everything typed with send-keys, Enter as a newline
+	Respawn string // command the pane was respawned with
+	Active  bool
+}
+
+func newFakeTmux() *fakeTmux {
+	return &fakeTmux{}
+}
+
+func (f *fakeTmux) HasSession(session string) bool {
+	return f.session(session) != nil
+}
+
+func (f *fakeTmux) Attach(session string) error {
+	f.Calls = append(f.Calls, []string{"attach-session", "-t", session})
+	return f.attach(session)
+}
+
+func (f *fakeTmux) SwitchClient(session string) error {
+	f.Calls = append(f.Calls, []string{"switch-client", "-t", session})
+	return f.attach(session)
+}
+
+func (f *fakeTmux) attach(session string) error {
+	if f.session(session) == nil {
+		return fmt.Errorf("can't find session: %s", session)
+	}
+	f.Attached = session
+	return nil
+}
+
+// call records a command by its tmux arguments and fails it when asked to.
+func (f *fakeTmux) call(args ...string) error {
+	f.Calls = append(f.Calls, args)
+	if msg, ok := f.Fail[args[0]]; ok {
+		return fmt.Errorf("tmux %s: %s", args[0], msg)
+	}
+	return nil
+}
+
+func (f *fakeTmux) NewSession(c newSession) (string, error) {
+	if err := f.call(c.args()...); err != nil {
+		return "", err
+	}
+	if f.session(c.Session) != nil {
+		return "", fmt.Errorf("duplicate session: %s", c.Session)
+	}
+	pane := f.newPane(c.Dir)
+	pane.Active = true
+	f.Sessions = append(f.Sessions, &fakeSession{
+		Name:    c.Session,
+		Windows: []*fakeWindow{{Panes: []*fakePane{pane}}},
+		Options: map[string]string{},
+	})
+	return pane.ID, nil
+}
+
+func (f *fakeTmux) SplitWindow(c splitWindow) (string, error) {
+	if err := f.call(c.args()...); err != nil {
+		return "", err
+	}
+	_, window, target := f.pane(c.Target)
+	if target == nil {
+		return "", fmt.Errorf("can't find pane: %s", c.Target)
+	}
+	direction := "-v"
+	if c.Horizontal {
+		direction = "-h"
+	}
+	pane := f.newPane(c.Dir)
+	pane.Split = fmt.Sprintf("%s %d%% of %s", direction, c.Percent, target.ID)
+	for i, p := range window.Panes {
+		if p == target {
+			window.Panes = append(window.Panes[:i+1], append([]*fakePane{pane}, window.Panes[i+1:]...)...)
+			break
+		}
+	}
+	return pane.ID, nil
+}
+
+func (f *fakeTmux) ResizePane(c resizePane) error {
+	if err := f.call(c.args()...); err != nil {
+		return err
+	}
+	_, _, pane := f.pane(c.Target)
+	if pane == nil {
+		return fmt.Errorf("can't find pane: %s", c.Target)
+	}
+	pane.Width = c.Width
+	return nil
+}
+
+func (f *fakeTmux) NewWindow(c newWindow) (string, error) {
+	if err := f.call(c.args()...); err != nil {
+		return "", err
+	}
+	sess := f.session(c.Session)
+	if sess == nil {
+		return "", fmt.Errorf("can't find session: %s", c.Session)
+	}
+	pane := f.newPane(c.Dir)
+	sess.Windows = append(sess.Windows, &fakeWindow{Name: c.Name, Panes: []*fakePane{pane}})
+	return pane.ID, nil
+}
+
+func (f *fakeTmux) SendKeys(c sendKeys) error {
+	if err := f.call(c.args()...); err != nil {
+		return err
+	}
+	_, _, pane := f.pane(c.Target)
+	if pane == nil {
+		return fmt.Errorf("can't find pane: %s", c.Target)
+	}
+	switch {
+	case c.Literal:
+		pane.Keys += c.Keys
+	case c.Keys == "Enter":
+		pane.Keys += "\n"
+	default:
+		return fmt.Errorf("fake tmux does not know key %s", c.Keys)
+	}
+	return nil
+}
+
+func (f *fakeTmux) RespawnPane(c respawnPane) error {
+	if err := f.call(c.args()...); err != nil {
+		return err
+	}
+	_, _, pane := f.pane(c.Target)
+	if pane == nil {
+		return fmt.Errorf("can't find pane: %s", c.Target)
+	}
+	pane.Respawn = c.Command[len(c.Command)-1]
+	return nil
+}
+
+func (f *fakeTmux) SetOption(c setOption) error {
+	if err := f.call(c.args()...); err != nil {
+		return err
+	}
+	if c.Pane {
+		_, _, pane := f.pane(c.Target)
+		if pane == nil {
+			return fmt.Errorf("can't find pane: %s", c.Target)
+		}
+		pane.Options[c.Name] = c.Value
+		return nil
+	}
+	sess := f.session(c.Target)
+	if sess == nil {
+		return fmt.Errorf("can't find session: %s", c.Target)
+	}
+	sess.Options[c.Name] = c.Value
+	return nil
+}
+
+func (f *fakeTmux) SelectPane(c selectPane) error {
+	if err := f.call(c.args()...); err != nil {
+		return err
+	}
+	return f.activate(c.Target)
+}
+
+func (f *fakeTmux) SelectWindow(target string) error {
+	if err := f.call("select-window", "-t", target); err != nil {
+		return err
+	}
+	return f.activate(target)
+}
+
+func (f *fakeTmux) activate(id string) error {
+	_, window, pane := f.pane(id)
+	if pane == nil {
+		return fmt.Errorf("can't find pane: %s", id)
+	}
+	for _, p := range window.Panes {
+		p.Active = p == pane
+	}
+	return nil
+}
+
+// CapturePane shows what was typed into the pane.
+func (f *fakeTmux) CapturePane(c capturePane) (string, error) {
+	if err := f.call(c.args()...); err != nil {
+		return "", err
+	}
+	_, _, pane := f.pane(c.Target)
+	if pane == nil {
+		return "", fmt.Errorf("can't find pane: %s", c.Target)
+	}
+	return pane.Keys, nil
+}
+
+func (f *fakeTmux) RenameSession(session, name string) error {
+	if err := f.call("rename-session", "-t", session+":", name); err != nil {
+		return err
+	}
+	sess := f.session(session)
+	if sess == nil {
+		return fmt.Errorf("can't find session: %s", session)
+	}
+	sess.Name = name
+	return nil
+}
+
+func (f *fakeTmux) KillSession(session string) error {
+	if err := f.call("kill-session", "-t", session+":"); err != nil {
+		return err
+	}
+	for i, sess := range f.Sessions {
+		if sess.Name == session {
+			f.Sessions = append(f.Sessions[:i], f.Sessions[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("can't find session: %s", session)
+}
+
+func (f *fakeTmux) ListSessions(vars ...string) ([]tmuxVars, error) {
+	if err := f.call("list-sessions", "-F", varsFormat(vars)); err != nil {
+		return nil, err
+	}
+	var out strings.Builder
+	for _, sess := range f.Sessions {
+		out.WriteString(f.format(vars, sess, sess.Windows[0].Panes[0]))
+	}
+	return parseVars(out.String(), vars), nil
+}
+
+func (f *fakeTmux) ListPanes(session string, vars ...string) ([]tmuxVars, error) {
+	args := []string{"list-panes", "-a", "-F", varsFormat(vars)}
+	if session != "" {
+		args = []string{"list-panes", "-s", "-t", session + ":", "-F", varsFormat(vars)}
+	}
+	if err := f.call(args...); err != nil {
+		return nil, err
+	}
+	sessions := f.Sessions
+	if session != "" {
+		sess := f.session(session)
+		if sess == nil {
+			return nil, fmt.Errorf("can't find session: %s", session)
+		}
+		sessions = []*fakeSession{sess}
+	}
+	var out strings.Builder
+	for _, sess := range sessions {
+		for _, window := range sess.Windows {
+			for _, pane := range window.Panes {
+				out.WriteString(f.format(vars, sess, pane))
+			}
+		}
+	}
+	return parseVars(out.String(), vars), nil
+}
+
+func (f *fakeTmux) SessionVars(session string, vars ...string) (tmuxVars, error) {
+	if err := f.call("display-message", "-p", "-t", session+":", varsFormat(vars)); err != nil {
+		return nil, err
+	}
+	sess := f.session(session)
+	if sess == nil {
+		return nil, fmt.Errorf("can't find session: %s", session)
+	}
+	return parseVars(f.format(vars, sess, sess.Windows[0].Panes[0]), vars)[0], nil
+}
+
+func (f *fakeTmux) newPane(dir string) *fakePane {
+	pane := &fakePane{ID: fmt.Sprintf("%%%d", f.nextPane), Dir: dir, Options: map[string]string{}}
+	f.nextPane++
+	return pane
+}
+
+func (f *fakeTmux) session(name string) *fakeSession {
+	for _, sess := range f.Sessions {
+		if sess.Name == name {
+			return sess
+		}
+	}
+	return nil
+}
+
+func (f *fakeTmux) pane(id string) (*fakeSession, *fakeWindow, *fakePane) {
+	for _, sess := range f.Sessions {
+		for _, window := range sess.Windows {
+			for _, pane := range window.Panes {
+				if pane.ID == id {
+					return sess, window, pane
+				}
+			}
+		}
+	}
+	return nil, nil, nil
+}
+
+// format prints the variables vinw-workspace queries the way tmux prints
+// varsFormat(vars): one line, fields separated by fieldSep.
+func (f *fakeTmux) format(vars []string, sess *fakeSession, pane *fakePane) string {
+	attached := "0"
+	if f.Attached == sess.Name {
+		attached = "1"
+	}
+	command := "bash"
+	if line, _, _ := strings.Cut(pane.Keys, "\n"); line != "" {
+		command, _, _ = strings.Cut(line, " ")
+	}
+	values := map[string]string{
+		"session_name":         sess.Name,
+		"session_path":         sess.Windows[0].Panes[0].Dir,
+		"session_windows":      fmt.Sprint(len(sess.Windows)),
+		"session_attached":     attached,
+		"pane_id":              pane.ID,
+		"pane_current_command": command,
+	}
+	for name, value := range sess.Options {
+		values[name] = value
+	}
+	for name, value := range pane.Options {
+		values[name] = value
+	}
+
+	fields := make([]string, len(vars))
+	for i, name := range vars {
+		fields[i] = values[name]
+	}
+	return strings.Join(fields, fieldSep) + "\n"
+}
+
+// String draws the server's sessions, windows and panes, one pane a line:
+// ID, how it was split off and resized, role, directory and what was
+// typed into it.
+func (f *fakeTmux) String() string {
+	var b strings.Builder
+	for _, sess := range f.Sessions {
+		fmt.Fprintf(&b, "session %s", sess.Name)
+		var options []string
+		for name, value := range sess.Options {
+			options = append(options, name+"="+value)
+		}
+		sort.Strings(options)
+		if len(options) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(options, " "))
+		}
+		if f.Attached == sess.Name {
+			b.WriteString(" (attached)")
+		}
+		b.WriteString("\n")
+
+		for i, window := range sess.Windows {
+			fmt.Fprintf(&b, "  window %d", i)
+			if window.Name != "" {
+				b.WriteString(" " + window.Name)
+			}
+			b.WriteString("\n")
+			for _, pane := range window.Panes {
+				fmt.Fprintf(&b, "    %s", pane.ID)
+				if pane.Split != "" {
+					fmt.Fprintf(&b, " split %s", pane.Split)
+				}
+				if pane.Width != 0 {
+					fmt.Fprintf(&b, " %d wide", pane.Width)
+				}
+				if role := pane.Options[roleOption]; role != "" {
+					fmt.Fprintf(&b, " role=%s", role)
+				}
+				if pane.Active {
+					b.WriteString(" active")
+				}
+				fmt.Fprintf(&b, " in %s\n", pane.Dir)
+				for _, line := range strings.Split(strings.TrimSuffix(pane.Keys, "\n"), "\n") {
+					if line != "" {
+						fmt.Fprintf(&b, "      $ %s\n", line)
+					}
+				}
+				if pane.Respawn != "" {
+					fmt.Fprintf(&b, "      respawn %s\n", pane.Respawn)
+				}
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Window is an extra tmux window opened after the main layout, for things
+// that run alongside the workspace: a dev server, logs, a test watcher.
+type Window struct {
+	Name    string      `json:"name"`
+	Command string      `json:"command"`
+	Dir     string      `json:"dir,omitempty"`   // relative to the workspace directory unless absolute
+	Ready   *ReadyCheck `json:"ready,omitempty"` // what to wait for before the next window starts
+	Project bool        `json:"-"`               // from a project file, replaced when the directory changes
+}
+
+// workDir returns the directory the window starts in for a workspace.
+func (w Window) workDir(workspaceDir string) string {
+	if w.Dir == "" {
+		return workspaceDir
+	}
+	dir := expandHome(w.Dir)
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(workspaceDir, dir)
+}
+
+// parseWindow parses a --window flag value of the form "name:command".
+func parseWindow(value string) (Window, error) {
+	name, command, ok := strings.Cut(value, ":")
+	name, command = strings.TrimSpace(name), strings.TrimSpace(command)
+	if !ok || name == "" || command == "" {
+		return Window{}, fmt.Errorf("invalid window %q, expected name:command", value)
+	}
+	return Window{Name: name, Command: command}, nil
+}
+
+// windowFlag collects repeated --window flags.
+type windowFlag []Window
+
+func (f *windowFlag) String() string {
+	names := make([]string, len(*f))
+	for i, w := range *f {
+		names[i] = w.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func (f *windowFlag) Set(value string) error {
+	window, err := parseWindow(value)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, window)
+	return nil
+}
+
+// mergeWindows combines window lists in order. A window named again in a
+// later list replaces the earlier one in place.
+func mergeWindows(lists ...[]Window) []Window {
+	var merged []Window
+	for _, list := range lists {
+		for _, window := range list {
+			replaced := false
+			for i := range merged {
+				if merged[i].Name == window.Name {
+					merged[i] = window
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				merged = append(merged, window)
+			}
+		}
+	}
+	return merged
+}
+
+// windowNames lists window names for the preview.
+func windowNames(windows []Window) []string {
+	names := make([]string, len(windows))
+	for i, w := range windows {
+		names[i] = w.Name
+	}
+	return names
+}
+
+// setProjectWindows swaps the windows from the previous project file for
+// the ones in project, keeping windows added by hand.
+func (m *model) setProjectWindows(project ProjectConfig) {
+	var windows []Window
+	for _, w := range m.windows {
+		if !w.Project {
+			windows = append(windows, w)
+		}
+	}
+	for _, w := range project.Windows {
+		w.Project = true
+		windows = mergeWindows(windows, []Window{w})
+	}
+	m.windows = windows
+}
+
+// setPresetWindows replaces the windows added by hand with a preset's.
+// Windows from the project file stay unless the preset names them too.
+func (m *model) setPresetWindows(windows []Window) {
+	var project []Window
+	for _, w := range m.windows {
+		if w.Project {
+			project = append(project, w)
+		}
+	}
+	m.windows = mergeWindows(project, windows)
+}
+
+// toggleCommandWindow opens a custom command in a window of its own, or
+// takes it out again.
+func (m *model) toggleCommandWindow(cmd WorkspaceCommand) {
+	for i, w := range m.windows {
+		if w.Name == cmd.Name {
+			m.windows = append(m.windows[:i:i], m.windows[i+1:]...)
+			return
+		}
+	}
+	m.windows = append(m.windows, Window{Name: cmd.Name, Command: cmd.Command})
+}
+
+// hasWindow reports whether a window with the given name will be opened.
+func (m model) hasWindow(name string) bool {
+	for _, w := range m.windows {
+		if w.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// runGit runs git in dir and returns its trimmed output. Errors carry git's
+// own message.
+func runGit(dir string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// worktreePath is where a new worktree for branch goes: under location
+// when it is configured, otherwise next to the repository in
+// <repo>-worktrees.
+func worktreePath(location, repoRoot, branch string) string {
+	name := strings.ReplaceAll(branch, "/", "-")
+	if location == "" {
+		return filepath.Join(filepath.Dir(repoRoot), filepath.Base(repoRoot)+"-worktrees", name)
+	}
+	return filepath.Join(expandPath(location), filepath.Base(repoRoot), name)
+}
+
+// branchSessionName turns a branch name into a tmux session name. tmux
+// does not allow "." or ":" in session names.
+func branchSessionName(branch string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune("/.: ", r) {
+			return '-'
+		}
+		return r
+	}, branch)
+}
+
+// findWorktree returns the worktree that has branch checked out, or "",
+// and whether that is the main worktree, which git lists first.
+func findWorktree(repoRoot, branch string) (string, bool, error) {
+	out, err := runGit(repoRoot, "worktree", "list", "--porcelain")
+	if err != nil {
+		return "", false, err
+	}
+
+	path, listed := "", 0
+	for _, line := range strings.Split(out, "\n") {
+		if p, ok := strings.CutPrefix(line, "worktree "); ok {
+			path = p
+			listed++
+		}
+		if line == "branch refs/heads/"+branch {
+			return path, listed == 1, nil
+		}
+	}
+	return "", false, nil
+}
+
+// worktreePlan is where a branch will be checked out for a launch.
+type worktreePlan struct {
+	Branch    string
+	Root      string   // repository containing the chosen directory
+	Path      string   // the worktree
+	LaunchDir string   // the chosen directory's counterpart inside the worktree
+	Add       []string // git arguments that create the worktree, nil when it exists
+}
+
+// planWorktree works out the worktree for branch in the repository that
+// contains dir without changing anything. A worktree that already has the
+// branch is reused; a branch that does not exist locally or on a remote is
+// created from HEAD. A branch checked out in the main worktree is refused,
+// since launching there would work in the main checkout.
+func planWorktree(dir, branch, location string) (worktreePlan, error) {
+	root, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return worktreePlan{}, fmt.Errorf("%s is not in a git repository", dir)
+	}
+	if _, err := runGit(root, "check-ref-format", "--branch", branch); err != nil {
+		return worktreePlan{}, fmt.Errorf("invalid branch name %q", branch)
+	}
+
+	// Keep the subdirectory the user picked, for monorepos
+	sub := "."
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		if rel, err := filepath.Rel(root, resolved); err == nil && !strings.HasPrefix(rel, "..") {
+			sub = rel
+		}
+	}
+
+	plan := worktreePlan{Branch: branch, Root: root}
+	var inMain bool
+	plan.Path, inMain, err = findWorktree(root, branch)
+	if err != nil {
+		return worktreePlan{}, err
+	}
+	if inMain {
+		return worktreePlan{}, fmt.Errorf("%s is checked out in the main worktree %s - launch without a worktree or switch that checkout to another branch", branch, plan.Path)
+	}
+	if plan.Path == "" {
+		plan.Path = worktreePath(location, root, branch)
+		plan.Add = []string{"worktree", "add", plan.Path, branch}
+		if !branchExists(root, branch) {
+			plan.Add = []string{"worktree", "add", "-b", branch, plan.Path}
+		}
+	}
+	plan.LaunchDir = filepath.Join(plan.Path, sub)
+	return plan, nil
+}
+
+// addCommand is the shell command that creates the worktree.
+func (p worktreePlan) addCommand() string {
+	words := append([]string{"git", "-C", p.Root}, p.Add...)
+	for i := range words {
+		words[i] = shellQuote(words[i])
+	}
+	return strings.Join(words, " ")
+}
+
+// create runs git worktree add when the worktree does not exist yet. A
+// new branch left behind by a failed try is checked out, not created again.
+func (p worktreePlan) create() error {
+	if p.Add == nil {
+		return nil
+	}
+	args := p.Add
+	if args[2] == "-b" && branchExists(p.Root, p.Branch) {
+		args = []string{"worktree", "add", p.Path, p.Branch}
+	}
+	_, err := runGit(p.Root, args...)
+	return err
+}
+
+// ensureWorktree checks out branch in a worktree as planned by
+// planWorktree. It returns the worktree and the directory to launch in.
+func ensureWorktree(dir, branch, location string) (string, string, error) {
+	plan, err := planWorktree(dir, branch, location)
+	if err != nil {
+		return "", "", err
+	}
+	if err := plan.create(); err != nil {
+		return "", "", fmt.Errorf("failed to create worktree for %s: %w", branch, err)
+	}
+	return plan.Path, plan.LaunchDir, nil
+}
+
+// branchExists reports whether branch exists locally or on a remote. git
+// worktree add sets up a tracking branch for a remote-only branch.
+func branchExists(repoRoot, branch string) bool {
+	if _, err := runGit(repoRoot, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+		return true
+	}
+	out, err := runGit(repoRoot, "for-each-ref", "--format=%(refname)", "refs/remotes/*/"+branch)
+	return err == nil && out != ""
+}
+
+// worktreeChanges counts modified and untracked files in a worktree.
+func worktreeChanges(worktree string) (int, error) {
+	out, err := runGit(worktree, "status", "--porcelain")
+	if err != nil {
+		return 0, err
+	}
+	if out == "" {
+		return 0, nil
+	}
+	return len(strings.Split(out, "\n")), nil
+}
+
+// removeWorktree deletes a worktree. Uncommitted changes are only thrown
+// away with force. The branch is kept.
+func removeWorktree(worktree string, force bool) error {
+	args := []string{"worktree", "remove", worktree}
+	if force {
+		args = append(args, "--force")
+	}
+	if _, err := runGit(worktree, args...); err != nil {
+		return fmt.Errorf("failed to remove worktree %s: %w", worktree, err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// gitRepo creates a repository with one commit on branch main.
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", args[0], err, out)
+		}
+	}
+	return dir
+}
+
+func TestPlanWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := gitRepo(t)
+	// git reports worktrees with symlinks resolved
+	location, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := planWorktree(repo, "main", location); err == nil || !strings.Contains(err.Error(), "checked out in the main worktree") {
+		t.Errorf("planning a worktree for the main checkout's branch: err = %v", err)
+	}
+
+	plan, err := planWorktree(repo, "feature/login", location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(location, filepath.Base(repo), "feature-login")
+	if plan.Path != want || strings.Join(plan.Add, " ") != "worktree add -b feature/login "+want {
+		t.Fatalf("plan = %+v, want a new branch in %s", plan, want)
+	}
+
+	// Once the worktree exists it is reused
+	if _, _, err := ensureWorktree(repo, "feature/login", location); err != nil {
+		t.Fatal(err)
+	}
+	plan, err = planWorktree(repo, "feature/login", location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Add != nil || plan.LaunchDir != want {
+		t.Errorf("plan = %+v, want the existing worktree %s reused", plan, want)
+	}
+}
+
+func TestLaunchCreatesWorktreeAsStep(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	// launch starts launching from the preview with the worktree for
+	// feature/login still to be created
+	launch := func(t *testing.T) (model, tea.Cmd, *fakeTmux, string) {
+		t.Setenv("TMUX", "")
+		fake := cliEnv(t)
+		installFakes(t, "vinw", "vinw-viewer", "tmux", "claude")
+		repo := gitRepo(t)
+		location, err := filepath.EvalSymlinks(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m := initialModel()
+		m.currentState = statePreview
+		m.directory = repo
+		m.config.WorktreeDir = location
+		m.inputs[0].SetValue("login")
+		m.inputs[1].SetValue("feature/login")
+		next, cmd := m.startLaunch(conflictFail)
+		m = next.(model)
+		if m.currentState != stateLaunching || !strings.Contains(m.View(), "create worktree for feature/login") {
+			t.Fatalf("state = %v, want the worktree listed on the launching screen:\n%s", m.currentState, m.View())
+		}
+		return m, cmd, fake, filepath.Join(location, filepath.Base(repo), "feature-login")
+	}
+
+	t.Run("retry", func(t *testing.T) {
+		m, cmd, _, worktree := launch(t)
+		// A file in the way makes git worktree add fail until it is moved
+		if err := os.MkdirAll(filepath.Dir(worktree), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(worktree, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		m, _ = runLaunching(m, cmd)
+		if m.launchRun.err == nil || !strings.Contains(m.launchRun.err.Error(), "failed to create worktree for feature/login") {
+			t.Fatalf("err = %v, want the launch stopped at the worktree", m.launchRun.err)
+		}
+		if view := m.View(); strings.Contains(view, "s: skip") || !strings.Contains(view, "esc: back") {
+			t.Errorf("a failed worktree offers to skip it or remove a session:\n%s", view)
+		}
+
+		if err := os.Remove(worktree); err != nil {
+			t.Fatal(err)
+		}
+		m, quit := pressKey(m, "r")
+		if !quit || m.attachTarget != "login" {
+			t.Fatalf("quit = %v, attach = %q after retry; want to attach to login: %v", quit, m.attachTarget, m.launchRun.err)
+		}
+		if _, err := os.Stat(filepath.Join(worktree, ".git")); err != nil {
+			t.Errorf("worktree was not created: %v", err)
+		}
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		m, cmd, fake, _ := launch(t)
+		m, _ = pressKey(m, "esc")
+		m, _ = runLaunching(m, cmd)
+		if m.currentState != statePreview || len(fake.Sessions) != 0 {
+			t.Errorf("state = %v after aborting the worktree; want the preview and no session:\n%s", m.currentState, fake)
+		}
+	})
+}